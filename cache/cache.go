@@ -0,0 +1,480 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"github.com/carter-ya/go-tools/concurrent"
+	"time"
+)
+
+// Cache is a bounded map which evicts entries by its policy, and expires entries by time.
+type Cache[K comparable, V any] interface {
+	// Get returns the value of the key, and records the access for the eviction policy and the statistics
+	Get(key K) (value V, found bool)
+	// Put adds or replaces the value of the key, it may evict other entries
+	Put(key K, value V)
+	// GetOrLoad returns the value of the key, or loads, stores and returns it if the key does not exist.
+	// The loaded value is not stored if the loader fails.
+	GetOrLoad(key K, loader func(key K) (V, error)) (V, error)
+	// Remove removes the key, and returns its value if the key exists
+	Remove(key K) (value V, found bool)
+	// ContainsKey returns true if the key exists, it is not an access
+	ContainsKey(key K) bool
+	// Keys returns the keys which have not expired, in no particular order
+	Keys() []K
+	// Size returns the number of entries which have not expired
+	Size() int
+	// Weight returns the total weight of the entries, which is the size if there is no weigher
+	Weight() int64
+	// Clear removes all entries
+	Clear()
+	// CleanUp removes the expired entries, which are otherwise removed by the next operation
+	CleanUp()
+	// Stats returns the statistics of the cache
+	Stats() Stats
+}
+
+// EvictionCause is the reason why an entry is removed
+type EvictionCause int
+
+const (
+	// RemovedExplicitly means the entry is removed by Remove or Clear
+	RemovedExplicitly EvictionCause = iota
+	// Replaced means the value is replaced by Put
+	Replaced
+	// Expired means the entry has expired
+	Expired
+	// EvictedBySize means the entry is evicted by the policy because the cache exceeds its max size or weight
+	EvictedBySize
+)
+
+// WasEvicted returns true if the entry was removed automatically, i.e. it expired or was evicted by size
+func (c EvictionCause) WasEvicted() bool {
+	return c == Expired || c == EvictedBySize
+}
+
+func (c EvictionCause) String() string {
+	switch c {
+	case RemovedExplicitly:
+		return "RemovedExplicitly"
+	case Replaced:
+		return "Replaced"
+	case Expired:
+		return "Expired"
+	case EvictedBySize:
+		return "EvictedBySize"
+	default:
+		return fmt.Sprintf("EvictionCause(%d)", int(c))
+	}
+}
+
+// Stats is the statistics of a cache
+type Stats struct {
+	// Hits is the number of Get calls which found the key
+	Hits int64
+	// Misses is the number of Get calls which did not find the key
+	Misses int64
+	// LoadSuccesses is the number of values loaded by GetOrLoad
+	LoadSuccesses int64
+	// LoadFailures is the number of loader errors of GetOrLoad
+	LoadFailures int64
+	// TotalLoadTime is the time spent by the loaders
+	TotalLoadTime time.Duration
+	// Evictions is the number of entries which expired or were evicted by size
+	Evictions int64
+}
+
+// Requests returns the number of Get calls
+func (s Stats) Requests() int64 {
+	return s.Hits + s.Misses
+}
+
+// HitRate returns the ratio of hits to requests, which is 1 if there is no request
+func (s Stats) HitRate() float64 {
+	if s.Requests() == 0 {
+		return 1
+	}
+	return float64(s.Hits) / float64(s.Requests())
+}
+
+// AverageLoadTime returns the average time spent by a loader
+func (s Stats) AverageLoadTime() time.Duration {
+	loads := s.LoadSuccesses + s.LoadFailures
+	if loads == 0 {
+		return 0
+	}
+	return s.TotalLoadTime / time.Duration(loads)
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("Stats{hits=%d, misses=%d, hitRate=%.4f, loadSuccesses=%d, loadFailures=%d, totalLoadTime=%s, evictions=%d}",
+		s.Hits, s.Misses, s.HitRate(), s.LoadSuccesses, s.LoadFailures, s.TotalLoadTime, s.Evictions)
+}
+
+// Option configures a cache
+type Option func(c *config)
+
+type config struct {
+	maxWeight         int64
+	weigher           any
+	expireAfterWrite  time.Duration
+	expireAfterAccess time.Duration
+	listener          any
+	clock             concurrent.Clock
+}
+
+// WithMaxSize sets the max number of entries
+func WithMaxSize(maxSize int) Option {
+	if maxSize <= 0 {
+		panic("cache: max size must be positive")
+	}
+	return func(c *config) {
+		c.maxWeight = int64(maxSize)
+		c.weigher = nil
+	}
+}
+
+// WithMaxWeight sets the max total weight of the entries, whose weights are computed by the weigher when they are put.
+func WithMaxWeight[K comparable, V any](maxWeight int64, weigher func(key K, value V) int64) Option {
+	if maxWeight <= 0 {
+		panic("cache: max weight must be positive")
+	}
+	if weigher == nil {
+		panic("cache: weigher must not be nil")
+	}
+	return func(c *config) {
+		c.maxWeight = maxWeight
+		c.weigher = weigher
+	}
+}
+
+// WithExpireAfterWrite expires an entry after the duration since it was put
+func WithExpireAfterWrite(ttl time.Duration) Option {
+	if ttl <= 0 {
+		panic("cache: ttl must be positive")
+	}
+	return func(c *config) {
+		c.expireAfterWrite = ttl
+	}
+}
+
+// WithExpireAfterAccess expires an entry after the duration since it was put or got
+func WithExpireAfterAccess(d time.Duration) Option {
+	if d <= 0 {
+		panic("cache: expire-after-access duration must be positive")
+	}
+	return func(c *config) {
+		c.expireAfterAccess = d
+	}
+}
+
+// WithEvictionListener sets the listener which is called synchronously after an entry is removed for any cause.
+func WithEvictionListener[K comparable, V any](listener func(key K, value V, cause EvictionCause)) Option {
+	return func(c *config) {
+		c.listener = listener
+	}
+}
+
+// WithClock sets the clock which tells the time for the expiration, the default is concurrent.SystemClock.
+func WithClock(clock concurrent.Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// entry is a key-value pair of a cache
+type entry[K comparable, V any] struct {
+	key    K
+	value  V
+	weight int64
+
+	writeExpiry  time.Time
+	accessExpiry time.Time
+	writeElem    *list.Element
+	accessElem   *list.Element
+
+	// the fields of the policy
+	policyElem *list.Element
+	bucket     *list.Element
+	segment    int
+}
+
+// baseCache is the Cache shared by all policies, it is not thread-safe, see SyncCache.
+type baseCache[K comparable, V any] struct {
+	entries map[K]*entry[K, V]
+	policy  policy[K, V]
+
+	maxWeight int64
+	weight    int64
+	weigher   func(key K, value V) int64
+
+	// the entries in the order of their expiration times
+	expireAfterWrite  time.Duration
+	expireAfterAccess time.Duration
+	writeOrder        *list.List
+	accessOrder       *list.List
+
+	listener func(key K, value V, cause EvictionCause)
+	clock    concurrent.Clock
+	stats    Stats
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{clock: concurrent.SystemClock}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func newBaseCache[K comparable, V any](c *config, p policy[K, V]) *baseCache[K, V] {
+	bc := &baseCache[K, V]{
+		entries:           make(map[K]*entry[K, V]),
+		policy:            p,
+		maxWeight:         c.maxWeight,
+		expireAfterWrite:  c.expireAfterWrite,
+		expireAfterAccess: c.expireAfterAccess,
+		writeOrder:        list.New(),
+		accessOrder:       list.New(),
+		clock:             c.clock,
+	}
+	if c.weigher != nil {
+		weigher, ok := c.weigher.(func(key K, value V) int64)
+		if !ok {
+			panic(fmt.Sprintf("cache: the weigher %T does not match the cache of %T", c.weigher, bc))
+		}
+		bc.weigher = weigher
+	}
+	if c.listener != nil {
+		listener, ok := c.listener.(func(key K, value V, cause EvictionCause))
+		if !ok {
+			panic(fmt.Sprintf("cache: the eviction listener %T does not match the cache of %T", c.listener, bc))
+		}
+		bc.listener = listener
+	}
+	return bc
+}
+
+func (c *baseCache[K, V]) Get(key K) (value V, found bool) {
+	c.expire()
+	c.policy.record(key)
+	e, found := c.entries[key]
+	if !found {
+		c.stats.Misses++
+		return value, false
+	}
+	c.stats.Hits++
+	c.touchAccess(e, c.clock.Now())
+	c.policy.onAccess(e)
+	return e.value, true
+}
+
+func (c *baseCache[K, V]) Put(key K, value V) {
+	c.expire()
+	c.policy.record(key)
+	c.put(key, value)
+}
+
+func (c *baseCache[K, V]) GetOrLoad(key K, loader func(key K) (V, error)) (V, error) {
+	if value, found := c.Get(key); found {
+		return value, nil
+	}
+	start := c.clock.Now()
+	value, err := loader(key)
+	c.stats.TotalLoadTime += c.clock.Now().Sub(start)
+	if err != nil {
+		c.stats.LoadFailures++
+		return value, err
+	}
+	c.stats.LoadSuccesses++
+	c.putLoaded(key, value)
+	return value, nil
+}
+
+func (c *baseCache[K, V]) Remove(key K) (value V, found bool) {
+	c.expire()
+	e, found := c.entries[key]
+	if !found {
+		return value, false
+	}
+	c.remove(e, RemovedExplicitly)
+	return e.value, true
+}
+
+func (c *baseCache[K, V]) ContainsKey(key K) bool {
+	c.expire()
+	_, found := c.entries[key]
+	return found
+}
+
+func (c *baseCache[K, V]) Keys() []K {
+	c.expire()
+	keys := make([]K, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (c *baseCache[K, V]) Size() int {
+	c.expire()
+	return len(c.entries)
+}
+
+func (c *baseCache[K, V]) Weight() int64 {
+	c.expire()
+	return c.weight
+}
+
+func (c *baseCache[K, V]) Clear() {
+	entries := c.entries
+	c.entries = make(map[K]*entry[K, V])
+	c.weight = 0
+	c.writeOrder.Init()
+	c.accessOrder.Init()
+	c.policy.clear()
+	if c.listener != nil {
+		for _, e := range entries {
+			c.listener(e.key, e.value, RemovedExplicitly)
+		}
+	}
+}
+
+func (c *baseCache[K, V]) CleanUp() {
+	c.expire()
+}
+
+func (c *baseCache[K, V]) Stats() Stats {
+	return c.stats
+}
+
+// putLoaded puts the loaded value, whose request was recorded by the Get before the load
+func (c *baseCache[K, V]) putLoaded(key K, value V) {
+	c.expire()
+	c.put(key, value)
+}
+
+func (c *baseCache[K, V]) clockOf() concurrent.Clock {
+	return c.clock
+}
+
+// put adds or replaces the entry of the key, and evicts the entries exceeding the max weight
+func (c *baseCache[K, V]) put(key K, value V) {
+	now := c.clock.Now()
+	weight := c.weigh(key, value)
+	if e, found := c.entries[key]; found {
+		oldValue := e.value
+		c.policy.onResize(e, weight-e.weight)
+		c.weight += weight - e.weight
+		e.value, e.weight = value, weight
+		c.touchWrite(e, now)
+		c.touchAccess(e, now)
+		c.policy.onAccess(e)
+		c.notify(key, oldValue, Replaced)
+		c.evict()
+		return
+	}
+
+	// evict before adding, so that the new entry is not the victim of its own insertion
+	for c.maxWeight > 0 && len(c.entries) > 0 && c.weight+weight > c.maxWeight {
+		c.remove(c.policy.victim(), EvictedBySize)
+	}
+	e := &entry[K, V]{key: key, value: value, weight: weight}
+	c.entries[key] = e
+	c.weight += weight
+	c.touchWrite(e, now)
+	c.touchAccess(e, now)
+	c.policy.onAdd(e)
+	// the entry is heavier than the max weight
+	c.evict()
+}
+
+func (c *baseCache[K, V]) weigh(key K, value V) int64 {
+	if c.weigher == nil {
+		return 1
+	}
+	weight := c.weigher(key, value)
+	if weight < 0 {
+		panic(fmt.Sprintf("cache: the weight of %v must not be negative", key))
+	}
+	return weight
+}
+
+// evict removes the victims of the policy until the total weight does not exceed the max weight
+func (c *baseCache[K, V]) evict() {
+	for c.maxWeight > 0 && c.weight > c.maxWeight {
+		c.remove(c.policy.victim(), EvictedBySize)
+	}
+}
+
+// expire removes the expired entries, which are at the front of the expiration orders
+func (c *baseCache[K, V]) expire() {
+	if c.expireAfterWrite <= 0 && c.expireAfterAccess <= 0 {
+		return
+	}
+	now := c.clock.Now()
+	for elem := c.writeOrder.Front(); elem != nil; elem = c.writeOrder.Front() {
+		e := elem.Value.(*entry[K, V])
+		if now.Before(e.writeExpiry) {
+			break
+		}
+		c.remove(e, Expired)
+	}
+	for elem := c.accessOrder.Front(); elem != nil; elem = c.accessOrder.Front() {
+		e := elem.Value.(*entry[K, V])
+		if now.Before(e.accessExpiry) {
+			break
+		}
+		c.remove(e, Expired)
+	}
+}
+
+// touchWrite moves the entry to the back of the write order
+func (c *baseCache[K, V]) touchWrite(e *entry[K, V], now time.Time) {
+	if c.expireAfterWrite <= 0 {
+		return
+	}
+	e.writeExpiry = now.Add(c.expireAfterWrite)
+	if e.writeElem == nil {
+		e.writeElem = c.writeOrder.PushBack(e)
+	} else {
+		c.writeOrder.MoveToBack(e.writeElem)
+	}
+}
+
+// touchAccess moves the entry to the back of the access order
+func (c *baseCache[K, V]) touchAccess(e *entry[K, V], now time.Time) {
+	if c.expireAfterAccess <= 0 {
+		return
+	}
+	e.accessExpiry = now.Add(c.expireAfterAccess)
+	if e.accessElem == nil {
+		e.accessElem = c.accessOrder.PushBack(e)
+	} else {
+		c.accessOrder.MoveToBack(e.accessElem)
+	}
+}
+
+func (c *baseCache[K, V]) remove(e *entry[K, V], cause EvictionCause) {
+	delete(c.entries, e.key)
+	c.weight -= e.weight
+	if e.writeElem != nil {
+		c.writeOrder.Remove(e.writeElem)
+		e.writeElem = nil
+	}
+	if e.accessElem != nil {
+		c.accessOrder.Remove(e.accessElem)
+		e.accessElem = nil
+	}
+	c.policy.onRemove(e)
+	if cause.WasEvicted() {
+		c.stats.Evictions++
+	}
+	c.notify(e.key, e.value, cause)
+}
+
+func (c *baseCache[K, V]) notify(key K, value V, cause EvictionCause) {
+	if c.listener != nil {
+		c.listener(key, value, cause)
+	}
+}
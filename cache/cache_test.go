@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"errors"
+	"github.com/carter-ya/go-tools/concurrent"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"testing"
+	"time"
+)
+
+type eviction struct {
+	key   string
+	value int
+	cause EvictionCause
+}
+
+func sortedKeys(c Cache[string, int]) []string {
+	keys := c.Keys()
+	sort.Strings(keys)
+	return keys
+}
+
+func TestLRUCache(t *testing.T) {
+	var evictions []eviction
+	c := NewLRUCache[string, int](WithMaxSize(3), WithEvictionListener(func(key string, value int, cause EvictionCause) {
+		evictions = append(evictions, eviction{key, value, cause})
+	}))
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+	c.Get("a")
+	c.Put("d", 4)
+	require.Equal(t, []string{"a", "c", "d"}, sortedKeys(c))
+
+	c.Put("c", 30)
+	c.Put("e", 5)
+	require.Equal(t, []string{"c", "d", "e"}, sortedKeys(c))
+	c.Remove("d")
+	require.Equal(t, []eviction{
+		{"b", 2, EvictedBySize},
+		{"c", 3, Replaced},
+		{"a", 1, EvictedBySize},
+		{"d", 4, RemovedExplicitly},
+	}, evictions)
+
+	value, found := c.Get("c")
+	require.True(t, found)
+	require.Equal(t, 30, value)
+	_, found = c.Get("a")
+	require.False(t, found)
+	require.Equal(t, Stats{Hits: 2, Misses: 1, Evictions: 2}, c.Stats())
+
+	c.Clear()
+	require.Equal(t, 0, c.Size())
+	require.Len(t, evictions, 6)
+}
+
+func TestLFUCache(t *testing.T) {
+	c := NewLFUCache[string, int](WithMaxSize(3))
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+	}
+	c.Get("b")
+	c.Get("c")
+	c.Get("c")
+
+	// d is never evicted by its own insertion, b is the least frequently used
+	c.Put("d", 4)
+	require.Equal(t, []string{"a", "c", "d"}, sortedKeys(c))
+	// d is the least frequently used
+	c.Put("e", 5)
+	require.Equal(t, []string{"a", "c", "e"}, sortedKeys(c))
+
+	// the least recently used one of the least frequently used entries is evicted
+	c.Get("e")
+	c.Put("f", 6)
+	c.Put("g", 7)
+	require.Equal(t, []string{"a", "c", "g"}, sortedKeys(c))
+}
+
+func TestCache_MaxWeight(t *testing.T) {
+	c := NewLRUCache[string, int](WithMaxWeight(10, func(key string, value int) int64 {
+		return int64(value)
+	}))
+	c.Put("a", 4)
+	c.Put("b", 4)
+	c.Put("c", 2)
+	require.Equal(t, int64(10), c.Weight())
+	c.Put("d", 3)
+	require.Equal(t, []string{"b", "c", "d"}, sortedKeys(c))
+
+	// the weight is computed again when the value is replaced
+	c.Put("c", 5)
+	require.Equal(t, []string{"c", "d"}, sortedKeys(c))
+	require.Equal(t, int64(8), c.Weight())
+
+	// an entry heavier than the max weight is evicted immediately
+	c.Put("e", 11)
+	require.Equal(t, 0, c.Size())
+	require.Equal(t, int64(0), c.Weight())
+
+	require.Panics(t, func() {
+		NewLRUCache[string, string](WithMaxWeight(10, func(key string, value int) int64 {
+			return 1
+		}))
+	})
+}
+
+func TestCache_Expiration(t *testing.T) {
+	clock := concurrent.NewFakeClock(time.Unix(0, 0))
+	var expired []string
+	c := NewLRUCache[string, int](
+		WithClock(clock),
+		WithExpireAfterWrite(time.Minute),
+		WithExpireAfterAccess(20*time.Second),
+		WithEvictionListener(func(key string, value int, cause EvictionCause) {
+			if cause == Expired {
+				expired = append(expired, key)
+			}
+		}),
+	)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	clock.Advance(15 * time.Second)
+	c.Get("a")
+	clock.Advance(15 * time.Second)
+	// the expired entries are removed by the next operation
+	require.Empty(t, expired)
+	require.Equal(t, []string{"a"}, c.Keys())
+	require.Equal(t, []string{"b"}, expired)
+
+	// a is accessed every 15s, but expires 1 minute after it was put
+	c.Get("a")
+	clock.Advance(15 * time.Second)
+	c.Get("a")
+	clock.Advance(10 * time.Second)
+	require.True(t, c.ContainsKey("a"))
+	c.Put("c", 3)
+	clock.Advance(5*time.Second + time.Millisecond)
+	c.CleanUp()
+	require.Equal(t, []string{"b", "a"}, expired)
+	require.Equal(t, []string{"c"}, c.Keys())
+	require.Equal(t, int64(2), c.Stats().Evictions)
+
+	// replacing the value restarts the expiration
+	clock.Advance(10 * time.Second)
+	c.Put("c", 30)
+	clock.Advance(15 * time.Second)
+	value, found := c.Get("c")
+	require.True(t, found)
+	require.Equal(t, 30, value)
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	clock := concurrent.NewFakeClock(time.Unix(0, 0))
+	c := NewLFUCache[string, int](WithMaxSize(10), WithClock(clock))
+	loader := func(key string) (int, error) {
+		clock.Advance(time.Second)
+		if key == "" {
+			return 0, errors.New("empty key")
+		}
+		return len(key), nil
+	}
+
+	value, err := c.GetOrLoad("abc", loader)
+	require.NoError(t, err)
+	require.Equal(t, 3, value)
+	value, err = c.GetOrLoad("abc", func(key string) (int, error) {
+		panic("the key exists")
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, value)
+	_, err = c.GetOrLoad("", loader)
+	require.EqualError(t, err, "empty key")
+	require.False(t, c.ContainsKey(""))
+
+	stats := c.Stats()
+	require.Equal(t, Stats{
+		Hits:          1,
+		Misses:        2,
+		LoadSuccesses: 1,
+		LoadFailures:  1,
+		TotalLoadTime: 2 * time.Second,
+	}, stats)
+	require.InDelta(t, 1.0/3, stats.HitRate(), 1e-9)
+	require.Equal(t, time.Second, stats.AverageLoadTime())
+}
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"container/list"
+	_map "github.com/carter-ya/go-tools/collection/map"
+)
+
+// policy decides which entry is evicted when the cache exceeds its max weight
+type policy[K comparable, V any] interface {
+	// record records a request of the key, whether it exists or not
+	record(key K)
+	// onAdd is called after an entry is added
+	onAdd(e *entry[K, V])
+	// onAccess is called after an entry is got or replaced
+	onAccess(e *entry[K, V])
+	// onResize is called before the weight of an entry changes by delta
+	onResize(e *entry[K, V], delta int64)
+	// onRemove is called after an entry is removed
+	onRemove(e *entry[K, V])
+	// victim returns the entry to evict, the cache must not be empty
+	victim() *entry[K, V]
+	// clear removes all entries
+	clear()
+}
+
+var _ Cache[int, any] = (*LRUCache[int, any])(nil)
+
+// LRUCache is a Cache which evicts the least recently used entry, it is not thread-safe, see SyncCache.
+//
+// The entries are kept by a LinkedHashMap in access order, whose eldest entry is the victim.
+type LRUCache[K comparable, V any] struct {
+	*baseCache[K, V]
+}
+
+// NewLRUCache creates an LRUCache, which is unbounded without WithMaxSize or WithMaxWeight.
+func NewLRUCache[K comparable, V any](opts ...Option) *LRUCache[K, V] {
+	return &LRUCache[K, V]{
+		baseCache: newBaseCache[K, V](newConfig(opts), newLRUPolicy[K, V]()),
+	}
+}
+
+// lruPolicy keeps the entries from the least to the most recently used
+type lruPolicy[K comparable, V any] struct {
+	entries *_map.LinkedHashMap[K, *entry[K, V]]
+}
+
+func newLRUPolicy[K comparable, V any]() *lruPolicy[K, V] {
+	return &lruPolicy[K, V]{entries: _map.NewLinkedHashMapWithAccessOrder[K, *entry[K, V]](0)}
+}
+
+func (p *lruPolicy[K, V]) record(K) {}
+
+func (p *lruPolicy[K, V]) onAdd(e *entry[K, V]) {
+	p.entries.Put(e.key, e)
+}
+
+func (p *lruPolicy[K, V]) onAccess(e *entry[K, V]) {
+	// getting the key moves it to the most recently used
+	p.entries.Get(e.key)
+}
+
+func (p *lruPolicy[K, V]) onResize(*entry[K, V], int64) {}
+
+func (p *lruPolicy[K, V]) onRemove(e *entry[K, V]) {
+	p.entries.Remove(e.key)
+}
+
+func (p *lruPolicy[K, V]) victim() *entry[K, V] {
+	_, e, _ := p.entries.Eldest()
+	return e
+}
+
+func (p *lruPolicy[K, V]) clear() {
+	p.entries.Clear()
+}
+
+var _ Cache[int, any] = (*LFUCache[int, any])(nil)
+
+// LFUCache is a Cache which evicts the least frequently used entry,
+// or the least recently used one among the least frequently used entries.
+// It is not thread-safe, see SyncCache.
+//
+// The frequency of an entry starts from 1 when it is added, so a new entry is never evicted by its own insertion,
+// but it is the next victim if the other entries are used more frequently, see NewTinyLFUCache for an alternative.
+type LFUCache[K comparable, V any] struct {
+	*baseCache[K, V]
+}
+
+// NewLFUCache creates an LFUCache, which is unbounded without WithMaxSize or WithMaxWeight.
+func NewLFUCache[K comparable, V any](opts ...Option) *LFUCache[K, V] {
+	return &LFUCache[K, V]{
+		baseCache: newBaseCache[K, V](newConfig(opts), newLFUPolicy[K, V]()),
+	}
+}
+
+// lfuBucket is the entries of the same frequency, from the most to the least recently used
+type lfuBucket struct {
+	frequency int
+	entries   *list.List
+}
+
+// lfuPolicy keeps the buckets in ascending order of frequency, so that all operations are O(1)
+type lfuPolicy[K comparable, V any] struct {
+	buckets *list.List
+}
+
+func newLFUPolicy[K comparable, V any]() *lfuPolicy[K, V] {
+	return &lfuPolicy[K, V]{buckets: list.New()}
+}
+
+func (p *lfuPolicy[K, V]) record(K) {}
+
+func (p *lfuPolicy[K, V]) onAdd(e *entry[K, V]) {
+	front := p.buckets.Front()
+	if front == nil || front.Value.(*lfuBucket).frequency != 1 {
+		front = p.buckets.PushFront(&lfuBucket{frequency: 1, entries: list.New()})
+	}
+	e.bucket = front
+	e.policyElem = front.Value.(*lfuBucket).entries.PushFront(e)
+}
+
+func (p *lfuPolicy[K, V]) onAccess(e *entry[K, V]) {
+	current := e.bucket
+	frequency := current.Value.(*lfuBucket).frequency + 1
+	next := current.Next()
+	if next == nil || next.Value.(*lfuBucket).frequency != frequency {
+		next = p.buckets.InsertAfter(&lfuBucket{frequency: frequency, entries: list.New()}, current)
+	}
+	p.onRemove(e)
+	e.bucket = next
+	e.policyElem = next.Value.(*lfuBucket).entries.PushFront(e)
+}
+
+func (p *lfuPolicy[K, V]) onResize(*entry[K, V], int64) {}
+
+func (p *lfuPolicy[K, V]) onRemove(e *entry[K, V]) {
+	bucket := e.bucket.Value.(*lfuBucket)
+	bucket.entries.Remove(e.policyElem)
+	if bucket.entries.Len() == 0 {
+		p.buckets.Remove(e.bucket)
+	}
+	e.bucket, e.policyElem = nil, nil
+}
+
+func (p *lfuPolicy[K, V]) victim() *entry[K, V] {
+	return p.buckets.Front().Value.(*lfuBucket).entries.Back().Value.(*entry[K, V])
+}
+
+func (p *lfuPolicy[K, V]) clear() {
+	p.buckets.Init()
+}
@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/carter-ya/go-tools/concurrent"
+	"sync"
+)
+
+var _ Cache[int, any] = (*SyncCache[int, any])(nil)
+
+// loadingCache is implemented by the caches of this package, so that SyncCache can load values as they do
+type loadingCache[K comparable, V any] interface {
+	// putLoaded puts the loaded value without recording another request of the key
+	putLoaded(key K, value V)
+	// clockOf returns the clock of the cache
+	clockOf() concurrent.Clock
+}
+
+// SyncCache is a routine-safe Cache which guards another Cache by a mutex.
+//
+// GetOrLoad calls the loader without holding the mutex, and the concurrent GetOrLoad calls of the same key
+// wait for the same load instead of calling their loaders. A panic of the loader is returned as an error.
+// A load is discarded if the key is put or removed, or the cache is cleared while loading,
+// so that a stale value never overwrites a newer one, but it is still returned to the waiting calls.
+//
+// The eviction listener of the guarded cache is called while the mutex is held, so it must not access the cache.
+type SyncCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	cache Cache[K, V]
+	loads map[K]*concurrent.Promise[V]
+	stats Stats
+}
+
+// NewSyncCache returns a routine-safe Cache guarding the given cache, which must not be used directly any more.
+func NewSyncCache[K comparable, V any](cache Cache[K, V]) *SyncCache[K, V] {
+	return &SyncCache[K, V]{
+		cache: cache,
+		loads: make(map[K]*concurrent.Promise[V]),
+	}
+}
+
+func (c *SyncCache[K, V]) Get(key K) (value V, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Get(key)
+}
+
+func (c *SyncCache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.loads, key)
+	c.cache.Put(key, value)
+}
+
+func (c *SyncCache[K, V]) GetOrLoad(key K, loader func(key K) (V, error)) (V, error) {
+	c.mu.Lock()
+	if value, found := c.cache.Get(key); found {
+		c.mu.Unlock()
+		return value, nil
+	}
+	if load, found := c.loads[key]; found {
+		c.mu.Unlock()
+		return load.Get()
+	}
+	load := concurrent.NewPromise[V]()
+	c.loads[key] = load
+	c.mu.Unlock()
+
+	clock := concurrent.SystemClock
+	lc, ok := c.cache.(loadingCache[K, V])
+	if ok {
+		clock = lc.clockOf()
+	}
+	start := clock.Now()
+	value, err := callLoader(loader, key)
+	elapsed := clock.Now().Sub(start)
+
+	c.mu.Lock()
+	// the load is replaced or deleted if it is invalidated
+	valid := c.loads[key] == load
+	if valid {
+		delete(c.loads, key)
+	}
+	c.stats.TotalLoadTime += elapsed
+	if err != nil {
+		c.stats.LoadFailures++
+	} else {
+		c.stats.LoadSuccesses++
+		if valid && ok {
+			lc.putLoaded(key, value)
+		} else if valid {
+			c.cache.Put(key, value)
+		}
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		load.CompleteExceptionally(err)
+	} else {
+		load.Complete(value)
+	}
+	return value, err
+}
+
+func (c *SyncCache[K, V]) Remove(key K) (value V, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.loads, key)
+	return c.cache.Remove(key)
+}
+
+func (c *SyncCache[K, V]) ContainsKey(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.ContainsKey(key)
+}
+
+func (c *SyncCache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Keys()
+}
+
+func (c *SyncCache[K, V]) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Size()
+}
+
+func (c *SyncCache[K, V]) Weight() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Weight()
+}
+
+func (c *SyncCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loads = make(map[K]*concurrent.Promise[V])
+	c.cache.Clear()
+}
+
+func (c *SyncCache[K, V]) CleanUp() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.CleanUp()
+}
+
+// Stats returns the statistics of the guarded cache, with the loads of the SyncCache
+func (c *SyncCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.cache.Stats()
+	stats.LoadSuccesses += c.stats.LoadSuccesses
+	stats.LoadFailures += c.stats.LoadFailures
+	stats.TotalLoadTime += c.stats.TotalLoadTime
+	return stats
+}
+
+// callLoader calls the loader, and converts a panic to an error so that the waiting calls are released
+func callLoader[K comparable, V any](loader func(key K) (V, error), key K) (value V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cache: loader panicked: %v", r)
+		}
+	}()
+	return loader(key)
+}
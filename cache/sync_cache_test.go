@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"errors"
+	"github.com/carter-ya/go-tools/concurrent"
+	"github.com/carter-ya/go-tools/stream/collector"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSyncCache_GetOrLoad(t *testing.T) {
+	c := NewSyncCache[string, int](NewLRUCache[string, int](WithMaxSize(10)))
+	var loads atomic.Int64
+	release := make(chan struct{})
+	loader := func(key string) (int, error) {
+		loads.Add(1)
+		<-release
+		return len(key), nil
+	}
+
+	wg := new(sync.WaitGroup)
+	results := make([]int, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := c.GetOrLoad("abc", loader)
+			require.NoError(t, err)
+			results[i] = value
+		}(i)
+	}
+	// wait for all calls to miss
+	for c.Stats().Misses < int64(len(results)) {
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int64(1), loads.Load())
+	require.Equal(t, []int{3, 3, 3, 3, 3, 3, 3, 3}, results)
+	stats := c.Stats()
+	require.Equal(t, int64(1), stats.LoadSuccesses)
+	value, found := c.Get("abc")
+	require.True(t, found)
+	require.Equal(t, 3, value)
+}
+
+func TestSyncCache_InvalidatedLoad(t *testing.T) {
+	for _, tc := range []struct {
+		invalidate func(c *SyncCache[string, int])
+		found      bool
+	}{
+		{invalidate: func(c *SyncCache[string, int]) { c.Put("a", 100) }, found: true},
+		{invalidate: func(c *SyncCache[string, int]) { c.Remove("a") }},
+		{invalidate: func(c *SyncCache[string, int]) { c.Clear() }},
+	} {
+		c := NewSyncCache[string, int](NewLRUCache[string, int](WithMaxSize(10)))
+		loading := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan int)
+		go func() {
+			value, err := c.GetOrLoad("a", func(key string) (int, error) {
+				close(loading)
+				<-release
+				return 1, nil
+			})
+			require.NoError(t, err)
+			done <- value
+		}()
+		<-loading
+		tc.invalidate(c)
+		close(release)
+
+		// the loaded value is returned but not stored
+		require.Equal(t, 1, <-done)
+		value, found := c.Get("a")
+		require.Equal(t, tc.found, found)
+		if found {
+			require.Equal(t, 100, value)
+		}
+		require.Equal(t, int64(1), c.Stats().LoadSuccesses)
+	}
+}
+
+func TestSyncCache_LoadWithCacheClock(t *testing.T) {
+	clock := concurrent.NewFakeClock(time.Unix(0, 0))
+	inner := NewTinyLFUCache[string, int](WithMaxSize(10), WithClock(clock))
+	c := NewSyncCache[string, int](inner)
+	value, err := c.GetOrLoad("a", func(key string) (int, error) {
+		clock.Advance(time.Second)
+		return 1, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, value)
+	require.Equal(t, time.Second, c.Stats().TotalLoadTime)
+
+	// the miss is the only request recorded by the load
+	sketch := inner.policy.(*tinyLFUPolicy[string, int]).sketch
+	require.Equal(t, uint8(1), sketch.estimate(collector.Hash("a")))
+}
+
+func TestSyncCache_LoadFailure(t *testing.T) {
+	c := NewSyncCache[string, int](NewTinyLFUCache[string, int](WithMaxSize(10)))
+	_, err := c.GetOrLoad("a", func(key string) (int, error) {
+		return 0, errors.New("not found")
+	})
+	require.EqualError(t, err, "not found")
+	_, err = c.GetOrLoad("a", func(key string) (int, error) {
+		panic("boom")
+	})
+	require.EqualError(t, err, "cache: loader panicked: boom")
+	require.False(t, c.ContainsKey("a"))
+	require.Equal(t, int64(2), c.Stats().LoadFailures)
+
+	// the key can be loaded again after the failures
+	value, err := c.GetOrLoad("a", func(key string) (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, value)
+}
+
+func TestSyncCache_Concurrency(t *testing.T) {
+	c := NewSyncCache[int, int](NewLFUCache[int, int](WithMaxSize(50)))
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				key := (worker*j + j) % 100
+				switch j % 4 {
+				case 0:
+					c.Put(key, j)
+				case 1:
+					c.Get(key)
+				case 2:
+					_, _ = c.GetOrLoad(key, func(key int) (int, error) {
+						return key, nil
+					})
+				default:
+					c.Remove(key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.LessOrEqual(t, c.Size(), 50)
+	require.Equal(t, int64(c.Size()), c.Weight())
+	require.Len(t, c.Keys(), c.Size())
+}
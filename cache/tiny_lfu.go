@@ -0,0 +1,251 @@
+package cache
+
+import (
+	"container/list"
+	"github.com/carter-ya/go-tools/stream/collector"
+)
+
+var _ Cache[int, any] = (*TinyLFUCache[int, any])(nil)
+
+// TinyLFUCache is a Cache with the W-TinyLFU policy, it is not thread-safe, see SyncCache.
+//
+// A new entry is put into a small LRU window, and the entries leaving the window compete with
+// the victims of the main segmented LRU, the one whose frequency estimated by a Count-Min Sketch is higher is kept.
+// So a burst of new keys cannot flush the frequently used entries like an LRUCache,
+// and a new key can replace a formerly popular entry unlike an LFUCache.
+type TinyLFUCache[K comparable, V any] struct {
+	*baseCache[K, V]
+}
+
+// NewTinyLFUCache creates a TinyLFUCache, it panics without WithMaxSize or WithMaxWeight.
+func NewTinyLFUCache[K comparable, V any](opts ...Option) *TinyLFUCache[K, V] {
+	c := newConfig(opts)
+	if c.maxWeight <= 0 {
+		panic("cache: W-TinyLFU requires a max size or weight")
+	}
+	return &TinyLFUCache[K, V]{
+		baseCache: newBaseCache[K, V](c, newTinyLFUPolicy[K, V](c.maxWeight)),
+	}
+}
+
+// the segments of tinyLFUPolicy
+const (
+	windowSegment = iota
+	probationSegment
+	protectedSegment
+)
+
+// tinyLFUPolicy keeps 1% of the max weight in the window, and 80% of the rest in the protected segment
+type tinyLFUPolicy[K comparable, V any] struct {
+	// sketch grows with the number of the entries, since the max weight may be far more than the entries
+	sketch *frequencySketch
+	size   int
+
+	window          *list.List
+	probation       *list.List
+	protected       *list.List
+	windowWeight    int64
+	protectedWeight int64
+	maxWindow       int64
+	maxProtected    int64
+}
+
+func newTinyLFUPolicy[K comparable, V any](maxWeight int64) *tinyLFUPolicy[K, V] {
+	maxWindow := maxWeight / 100
+	if maxWindow < 1 {
+		maxWindow = 1
+	}
+	return &tinyLFUPolicy[K, V]{
+		sketch:       newFrequencySketch(0),
+		window:       list.New(),
+		probation:    list.New(),
+		protected:    list.New(),
+		maxWindow:    maxWindow,
+		maxProtected: (maxWeight - maxWindow) * 8 / 10,
+	}
+}
+
+func (p *tinyLFUPolicy[K, V]) record(key K) {
+	p.sketch.increment(collector.Hash(key))
+}
+
+func (p *tinyLFUPolicy[K, V]) onAdd(e *entry[K, V]) {
+	p.size++
+	p.sketch.ensureCapacity(p.size)
+	e.segment = windowSegment
+	e.policyElem = p.window.PushFront(e)
+	p.windowWeight += e.weight
+	// the entries leaving the window become the candidates at the front of the probation segment
+	for p.windowWeight > p.maxWindow && p.window.Len() > 1 {
+		candidate := p.window.Remove(p.window.Back()).(*entry[K, V])
+		p.windowWeight -= candidate.weight
+		candidate.segment = probationSegment
+		candidate.policyElem = p.probation.PushFront(candidate)
+	}
+}
+
+func (p *tinyLFUPolicy[K, V]) onAccess(e *entry[K, V]) {
+	switch e.segment {
+	case windowSegment:
+		p.window.MoveToFront(e.policyElem)
+	case probationSegment:
+		// an entry used again on probation is promoted, and the protected segment demotes its least recently used ones
+		p.probation.Remove(e.policyElem)
+		e.segment = protectedSegment
+		e.policyElem = p.protected.PushFront(e)
+		p.protectedWeight += e.weight
+		for p.protectedWeight > p.maxProtected && p.protected.Len() > 1 {
+			demoted := p.protected.Remove(p.protected.Back()).(*entry[K, V])
+			p.protectedWeight -= demoted.weight
+			demoted.segment = probationSegment
+			demoted.policyElem = p.probation.PushFront(demoted)
+		}
+	case protectedSegment:
+		p.protected.MoveToFront(e.policyElem)
+	}
+}
+
+func (p *tinyLFUPolicy[K, V]) onResize(e *entry[K, V], delta int64) {
+	switch e.segment {
+	case windowSegment:
+		p.windowWeight += delta
+	case protectedSegment:
+		p.protectedWeight += delta
+	}
+}
+
+func (p *tinyLFUPolicy[K, V]) onRemove(e *entry[K, V]) {
+	p.size--
+	switch e.segment {
+	case windowSegment:
+		p.window.Remove(e.policyElem)
+		p.windowWeight -= e.weight
+	case probationSegment:
+		p.probation.Remove(e.policyElem)
+	case protectedSegment:
+		p.protected.Remove(e.policyElem)
+		p.protectedWeight -= e.weight
+	}
+	e.policyElem = nil
+}
+
+// victim returns the less frequently used one of the newest candidate and the oldest entry on probation,
+// the candidate must be strictly more frequent to be admitted.
+func (p *tinyLFUPolicy[K, V]) victim() *entry[K, V] {
+	switch {
+	case p.probation.Len() == 0 && p.protected.Len() > 0:
+		return p.protected.Back().Value.(*entry[K, V])
+	case p.probation.Len() == 0:
+		return p.window.Back().Value.(*entry[K, V])
+	case p.probation.Len() == 1:
+		return p.probation.Front().Value.(*entry[K, V])
+	}
+
+	candidate := p.probation.Front().Value.(*entry[K, V])
+	victim := p.probation.Back().Value.(*entry[K, V])
+	if p.sketch.estimate(collector.Hash(candidate.key)) > p.sketch.estimate(collector.Hash(victim.key)) {
+		return victim
+	}
+	return candidate
+}
+
+func (p *tinyLFUPolicy[K, V]) clear() {
+	p.window.Init()
+	p.probation.Init()
+	p.protected.Init()
+	p.windowWeight, p.protectedWeight = 0, 0
+	p.size = 0
+}
+
+const (
+	sketchDepth   = 4
+	maxFrequency  = 15
+	minSketchSize = 64
+	maxSketchSize = 1 << 20
+)
+
+// frequencySketch is a Count-Min Sketch of saturating counters, which halves all counters periodically,
+// so that the frequencies reflect the recent requests.
+type frequencySketch struct {
+	counters  [sketchDepth][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+// newFrequencySketch creates a sketch with 8 counters per entry of the capacity to reduce the collisions,
+// whose counters are halved after 10 times the capacity of additions.
+func newFrequencySketch(capacity int) *frequencySketch {
+	s := &frequencySketch{mask: minSketchSize - 1, resetAt: 10 * minSketchSize / 8}
+	for i := range s.counters {
+		s.counters[i] = make([]uint8, minSketchSize)
+	}
+	s.ensureCapacity(capacity)
+	return s
+}
+
+// ensureCapacity grows the sketch to 8 counters per entry of the capacity.
+// The index of a hash in a doubled row has the same low bits as in the old row,
+// so the counters are copied to each half of the doubled row to keep the estimates.
+func (s *frequencySketch) ensureCapacity(capacity int) {
+	if capacity > maxSketchSize {
+		capacity = maxSketchSize
+	}
+	width := len(s.counters[0])
+	if width >= 8*capacity {
+		return
+	}
+	for width < 8*capacity {
+		width <<= 1
+	}
+	for i, row := range s.counters {
+		grown := make([]uint8, width)
+		for j := 0; j < width; j += len(row) {
+			copy(grown[j:], row)
+		}
+		s.counters[i] = grown
+	}
+	s.mask = uint64(width - 1)
+	s.resetAt = 10 * width / 8
+}
+
+// index returns the index of the hash in the i-th row by double hashing
+func (s *frequencySketch) index(hash uint64, i int) uint64 {
+	return (hash + uint64(i)*(hash>>32|1)) & s.mask
+}
+
+func (s *frequencySketch) increment(hash uint64) {
+	added := false
+	for i := range s.counters {
+		if counter := &s.counters[i][s.index(hash, i)]; *counter < maxFrequency {
+			*counter++
+			added = true
+		}
+	}
+	if !added {
+		return
+	}
+	if s.additions++; s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+func (s *frequencySketch) estimate(hash uint64) uint8 {
+	frequency := uint8(maxFrequency)
+	for i := range s.counters {
+		if counter := s.counters[i][s.index(hash, i)]; counter < frequency {
+			frequency = counter
+		}
+	}
+	return frequency
+}
+
+// reset halves all counters
+func (s *frequencySketch) reset() {
+	for i := range s.counters {
+		for j := range s.counters[i] {
+			s.counters[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
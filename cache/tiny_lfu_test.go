@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTinyLFUCache_ScanResistance(t *testing.T) {
+	lru := NewLRUCache[string, int](WithMaxSize(100))
+	tinyLFU := NewTinyLFUCache[string, int](WithMaxSize(100))
+	for _, c := range []Cache[string, int]{lru, tinyLFU} {
+		for round := 0; round < 10; round++ {
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("hot-%d", i)
+				if _, found := c.Get(key); !found {
+					c.Put(key, i)
+				}
+			}
+		}
+		// a scan of keys used once
+		for i := 0; i < 1000; i++ {
+			c.Put(fmt.Sprintf("cold-%d", i), i)
+		}
+	}
+
+	countHot := func(c Cache[string, int]) int {
+		count := 0
+		for i := 0; i < 50; i++ {
+			if c.ContainsKey(fmt.Sprintf("hot-%d", i)) {
+				count++
+			}
+		}
+		return count
+	}
+	require.Equal(t, 0, countHot(lru))
+	// a few hot keys may collide with the cold ones in the sketch
+	require.GreaterOrEqual(t, countHot(tinyLFU), 45)
+	require.Equal(t, 100, tinyLFU.Size())
+}
+
+func TestTinyLFUCache_Admission(t *testing.T) {
+	c := NewTinyLFUCache[int, int](WithMaxSize(10))
+	for i := 0; i < 10; i++ {
+		c.Put(i, i)
+	}
+	for i := 0; i < 5; i++ {
+		c.Get(100)
+	}
+	// the candidates leaving the window are not more frequent than the oldest entry on probation
+	c.Put(100, 100)
+	c.Put(101, 101)
+	require.False(t, c.ContainsKey(8))
+	require.False(t, c.ContainsKey(9))
+	// the frequent candidate 100 is admitted, and the oldest entry on probation is evicted
+	c.Put(102, 102)
+	require.True(t, c.ContainsKey(100))
+	require.False(t, c.ContainsKey(0))
+	require.Equal(t, 10, c.Size())
+	require.Equal(t, int64(3), c.Stats().Evictions)
+
+	require.Panics(t, func() {
+		NewTinyLFUCache[int, int]()
+	})
+}
+
+func TestFrequencySketch(t *testing.T) {
+	s := newFrequencySketch(64)
+	for i := 0; i < 20; i++ {
+		s.increment(1)
+	}
+	s.increment(2)
+	require.Equal(t, uint8(maxFrequency), s.estimate(1))
+	require.Equal(t, uint8(1), s.estimate(2))
+	require.Equal(t, uint8(0), s.estimate(3))
+
+	// the counters are halved after 10 times the capacity of additions
+	for i := uint64(0); i < 640; i++ {
+		s.increment(100 + i)
+	}
+	require.Equal(t, uint8(maxFrequency/2), s.estimate(1))
+}
+
+func TestTinyLFUCache_LargeMaxWeight(t *testing.T) {
+	c := NewTinyLFUCache[int, []byte](WithMaxWeight(1<<30, func(key int, value []byte) int64 {
+		return int64(len(value))
+	}))
+	sketch := c.policy.(*tinyLFUPolicy[int, []byte]).sketch
+	// the sketch is sized by the entries instead of the max weight
+	require.Len(t, sketch.counters[0], minSketchSize)
+	for i := 0; i < 100; i++ {
+		c.Put(i, make([]byte, 1024))
+	}
+	require.Len(t, sketch.counters[0], 1024)
+	require.Equal(t, 1280, sketch.resetAt)
+}
+
+func TestFrequencySketch_EnsureCapacity(t *testing.T) {
+	s := newFrequencySketch(0)
+	for i := uint64(0); i < 8; i++ {
+		for j := uint64(0); j <= i; j++ {
+			s.increment(i * 0x9e3779b97f4a7c15)
+		}
+	}
+	s.ensureCapacity(100)
+	require.Len(t, s.counters[0], 1024)
+	// the estimates are kept by the growth
+	for i := uint64(0); i < 8; i++ {
+		require.GreaterOrEqual(t, s.estimate(i*0x9e3779b97f4a7c15), uint8(i+1))
+	}
+}
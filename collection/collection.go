@@ -43,4 +43,6 @@ type Collection[E comparable] interface {
 	AsSlice() []E
 	// Stream returns a stream of the elements in the collection.
 	Stream() stream.Stream
+	// Iterator returns an iterator over the elements in the collection.
+	Iterator() Iterator[E]
 }
@@ -0,0 +1,17 @@
+//go:build go1.23
+
+package collection
+
+import "iter"
+
+// All returns an iter.Seq over the elements of the collection
+func All[E comparable](c Collection[E]) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		it := c.Iterator()
+		for e, ok := it.Next(); ok; e, ok = it.Next() {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
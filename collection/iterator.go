@@ -0,0 +1,43 @@
+package collection
+
+// Iterator iterates over the elements of a collection step by step.
+type Iterator[E any] interface {
+	// Next returns the next element.
+	// Returns false if there are no more elements.
+	Next() (e E, ok bool)
+	// Remove removes the element last returned by Next from the collection.
+	// It panics if Next has not returned an element, or the element has been removed.
+	Remove()
+}
+
+// SliceIterator is an iterator over a slice, the removed elements are reported to the remove function.
+type SliceIterator[E any] struct {
+	elements []E
+	index    int
+	removed  bool
+	remove   func(e E)
+}
+
+// NewSliceIterator returns an iterator over the given elements.
+// Remove calls the remove function with the element last returned by Next.
+func NewSliceIterator[E any](elements []E, remove func(e E)) *SliceIterator[E] {
+	return &SliceIterator[E]{elements: elements, remove: remove}
+}
+
+func (it *SliceIterator[E]) Next() (e E, ok bool) {
+	if it.index >= len(it.elements) {
+		return e, false
+	}
+	e = it.elements[it.index]
+	it.index++
+	it.removed = false
+	return e, true
+}
+
+func (it *SliceIterator[E]) Remove() {
+	if it.index == 0 || it.removed {
+		panic("collection: Remove must be called after Next")
+	}
+	it.removed = true
+	it.remove(it.elements[it.index-1])
+}
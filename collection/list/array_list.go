@@ -199,6 +199,10 @@ func (al *ArrayList[E]) Stream() stream.Stream {
 	return stream.Just(al.data)
 }
 
+func (al *ArrayList[E]) Iterator() collection.Iterator[E] {
+	return &arrayListIterator[E]{al: al, last: -1}
+}
+
 func (al *ArrayList[E]) String() string {
 	return collection.String[E](al)
 }
@@ -216,3 +220,28 @@ func (al *ArrayList[E]) UnmarshalJSON(data []byte) error {
 	al.data = items
 	return nil
 }
+
+// arrayListIterator iterates over the list by index, so that the elements can be removed while iterating
+type arrayListIterator[E comparable] struct {
+	al    *ArrayList[E]
+	index int
+	last  int
+}
+
+func (it *arrayListIterator[E]) Next() (e E, ok bool) {
+	if it.index >= len(it.al.data) {
+		return e, false
+	}
+	it.last = it.index
+	it.index++
+	return it.al.data[it.last], true
+}
+
+func (it *arrayListIterator[E]) Remove() {
+	if it.last < 0 {
+		panic("collection: Remove must be called after Next")
+	}
+	it.al.RemoveAt(it.last)
+	it.index = it.last
+	it.last = -1
+}
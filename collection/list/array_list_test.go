@@ -127,6 +127,24 @@ func TestArrayList_ContainsAll(t *testing.T) {
 	require.False(t, l.ContainsAll(l2))
 }
 
+func TestArrayList_Iterator(t *testing.T) {
+	l := NewArrayListFromSlice([]int{1, 2, 3, 4, 5})
+	it := l.Iterator()
+	var items []int
+	for e, ok := it.Next(); ok; e, ok = it.Next() {
+		items = append(items, e)
+		if e%2 == 0 {
+			it.Remove()
+		}
+	}
+	require.Equal(t, []int{1, 2, 3, 4, 5}, items)
+	require.Equal(t, []int{1, 3, 5}, l.AsSlice())
+
+	require.Panics(t, func() {
+		l.Iterator().Remove()
+	})
+}
+
 func TestArrayList_String(t *testing.T) {
 	l := NewArrayList[int]()
 	l.Add(1)
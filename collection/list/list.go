@@ -57,4 +57,6 @@ type List[E comparable] interface {
 	AsSlice() []E
 	// Stream returns a stream of the elements in the list.
 	Stream() stream.Stream
+	// Iterator returns an iterator over the elements in the list.
+	Iterator() collection.Iterator[E]
 }
@@ -0,0 +1,89 @@
+package _map
+
+import "github.com/carter-ya/go-tools/stream/collector"
+
+// NewOrderedFrequencyCollector returns a Collector that counts the elements of the stream by their keys
+// into a LinkedHashMap, whose keys are in the order of their first occurrence,
+// see collector.NewFrequencyCollector.
+//
+// The order is kept only if the stream is sequential or ordered, see stream.WithOrdered,
+// since the workers of an unordered parallel stream count the elements out of order.
+//
+// Note: The returned Collector is not routine-safe.
+func NewOrderedFrequencyCollector[T any, K comparable](
+	keyMapper func(T) K) collector.Collector[T, *LinkedHashMap[K, int], *LinkedHashMap[K, int]] {
+	return collector.NewBaseParallelCollector[T, *LinkedHashMap[K, int], *LinkedHashMap[K, int]](
+		func() *LinkedHashMap[K, int] {
+			return NewLinkedHashMap[K, int]()
+		},
+		func(container *LinkedHashMap[K, int], item T) {
+			key := keyMapper(item)
+			container.Put(key, container.GetOrDefault(key, 0)+1)
+		},
+		func(a *LinkedHashMap[K, int], b *LinkedHashMap[K, int]) *LinkedHashMap[K, int] {
+			b.ForEach(func(key K, count int) {
+				a.Put(key, a.GetOrDefault(key, 0)+count)
+			})
+			return a
+		},
+		func(container *LinkedHashMap[K, int]) *LinkedHashMap[K, int] {
+			return container
+		},
+	)
+}
+
+// NewConcurrentGroupByCollector returns a Collector that groups the elements of the stream by their keys
+// into a ConcurrentHashMap, see collector.NewGroupByCollector.
+//
+// Note: The returned Collector is routine-safe, so all workers of a parallel stream accumulate into
+// the same container, and no containers are merged at the end.
+func NewConcurrentGroupByCollector[T any, K comparable](
+	keyMapper func(T) K) collector.Collector[T, *ConcurrentHashMap[K, []T], *ConcurrentHashMap[K, []T]] {
+	return collector.NewBaseCollector[T, *ConcurrentHashMap[K, []T], *ConcurrentHashMap[K, []T]](
+		func() *ConcurrentHashMap[K, []T] {
+			return NewConcurrentHashMap[K, []T]()
+		},
+		func(container *ConcurrentHashMap[K, []T], item T) {
+			container.Merge(keyMapper(item), []T{item}, func(oldValue []T, value []T) ([]T, RemappingAction) {
+				return append(oldValue, value...), Replace
+			})
+		},
+		func(container *ConcurrentHashMap[K, []T]) *ConcurrentHashMap[K, []T] {
+			return container
+		},
+	)
+}
+
+// ConcurrentGroupingBy returns a Collector that groups the elements of the stream by their keys
+// into a ConcurrentHashMap, and collects the elements of each group by the downstream collector,
+// see collector.GroupingBy.
+//
+// Note: The returned Collector is routine-safe, the downstream accumulator is called while the key is locked,
+// so the downstream collector does not need to be routine-safe.
+func ConcurrentGroupingBy[T any, K comparable, A any, R any](
+	keyMapper func(T) K,
+	downstream collector.Collector[T, A, R],
+) collector.Collector[T, *ConcurrentHashMap[K, A], map[K]R] {
+	supplier, accumulator, finisher := downstream.Supplier(), downstream.Accumulator(), downstream.Finisher()
+	return collector.NewBaseCollector[T, *ConcurrentHashMap[K, A], map[K]R](
+		func() *ConcurrentHashMap[K, A] {
+			return NewConcurrentHashMap[K, A]()
+		},
+		func(container *ConcurrentHashMap[K, A], item T) {
+			container.Compute(keyMapper(item), func(_ K, group A, found bool) (A, RemappingAction) {
+				if !found {
+					group = supplier()
+				}
+				accumulator(group, item)
+				return group, Replace
+			})
+		},
+		func(container *ConcurrentHashMap[K, A]) map[K]R {
+			result := make(map[K]R, container.Size())
+			container.ForEach(func(key K, group A) {
+				result[key] = finisher(group)
+			})
+			return result
+		},
+	)
+}
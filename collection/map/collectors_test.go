@@ -0,0 +1,48 @@
+package _map
+
+import (
+	"github.com/carter-ya/go-tools/stream"
+	"github.com/carter-ya/go-tools/stream/collector"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewOrderedFrequencyCollector(t *testing.T) {
+	m := stream.Collect(stream.Of([]string{"b", "a", "b", "c", "a", "b"}),
+		NewOrderedFrequencyCollector[string](func(s string) string { return s }),
+	)
+	require.Equal(t, []string{"b", "a", "c"}, m.Keys())
+	require.Equal(t, []int{3, 2, 1}, m.Values())
+
+	// an ordered parallel stream keeps the order of the first occurrences
+	numbers := make([]int, 1000)
+	for i := range numbers {
+		numbers[i] = i % 100
+	}
+	m2 := stream.Collect(stream.Of(numbers, stream.WithParallelism(8), stream.WithOrdered()),
+		NewOrderedFrequencyCollector[int](collector.Identify[int]()),
+	)
+	for i, key := range m2.Keys() {
+		require.Equal(t, i, key)
+	}
+	require.Equal(t, 100, m2.Size())
+}
+
+func TestNewConcurrentGroupByCollector(t *testing.T) {
+	numbers := make([]int, 1000)
+	for i := range numbers {
+		numbers[i] = i
+	}
+	m := stream.Collect(stream.Of(numbers, stream.WithParallelism(8)),
+		NewConcurrentGroupByCollector[int](func(n int) int { return n % 3 }),
+	)
+	require.Equal(t, 3, m.Size())
+	for _, group := range m.Values() {
+		require.InDelta(t, 333, len(group), 1)
+	}
+
+	counts := stream.Collect(stream.Of(numbers, stream.WithParallelism(8)),
+		ConcurrentGroupingBy[int](func(n int) bool { return n%2 == 0 }, collector.NewCountCollector[int]()),
+	)
+	require.Equal(t, map[bool]int{true: 500, false: 500}, counts)
+}
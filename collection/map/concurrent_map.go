@@ -0,0 +1,455 @@
+package _map
+
+import (
+	"github.com/carter-ya/go-tools/collection"
+	"github.com/carter-ya/go-tools/stream/collector"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultShardCount is the number of shards of a ConcurrentHashMap created without a shard count
+const DefaultShardCount = 32
+
+// ConcurrentMap is a routine-safe Map whose compound operations are atomic.
+//
+// The mapping and remapping functions are called while the key is locked,
+// so they must be short and must not access the map.
+type ConcurrentMap[K comparable, V any] interface {
+	Map[K, V]
+
+	// PutIfAbsentAndGet is like PutIfAbsent, but returns the existing value if the key exists.
+	PutIfAbsentAndGet(key K, newValue V) (oldValue V, oldValueFound bool)
+	// ComputeIfAbsentAndGet is like ComputeIfAbsent, but returns the existing or computed value.
+	ComputeIfAbsentAndGet(key K, mapping func(key K) V) V
+	// Compute computes the value for the given key whether it exists or not,
+	// and returns the value associated with the key after the computation.
+	Compute(key K, remapping func(key K, oldValue V, found bool) (newValue V, action RemappingAction)) (value V, found bool)
+	// Merge puts the value if the key does not exist, otherwise merges the old value with it,
+	// and returns the value associated with the key after the merge.
+	Merge(key K, value V, remapping func(oldValue V, value V) (newValue V, action RemappingAction)) (newValue V, found bool)
+}
+
+var _ ConcurrentMap[int, any] = (*ConcurrentHashMap[int, any])(nil)
+
+// ConcurrentHashMap is a ConcurrentMap which stripes the keys over shards, each guarded by its own lock,
+// so that writers of different shards never block each other.
+//
+// ForEach, ForEachIndexed, Keys, Values and Iterator are weakly consistent:
+// they copy one shard at a time and call the consumer without holding any lock,
+// so they never block writers for longer than a shard copy, and they may or may not see concurrent changes.
+//
+// The zero value is an empty map with DefaultShardCount shards.
+type ConcurrentHashMap[K comparable, V any] struct {
+	once   sync.Once
+	shards []*concurrentShard[K, V]
+	hasher func(key K) uint64
+	size   atomic.Int64
+}
+
+type concurrentShard[K comparable, V any] struct {
+	sync.RWMutex
+	items map[K]V
+}
+
+func NewConcurrentHashMap[K comparable, V any]() *ConcurrentHashMap[K, V] {
+	return NewConcurrentHashMapWithShards[K, V](DefaultShardCount)
+}
+
+// NewConcurrentHashMapWithShards creates a ConcurrentHashMap with the given number of shards,
+// which is rounded up to a power of two.
+func NewConcurrentHashMapWithShards[K comparable, V any](shards int) *ConcurrentHashMap[K, V] {
+	return NewConcurrentHashMapWithHasher[K, V](shards, nil)
+}
+
+// NewConcurrentHashMapWithHasher creates a ConcurrentHashMap with the given number of shards
+// and the function which hashes the keys to the shards.
+//
+// The default hasher hashes strings, numbers, booleans and pointers directly,
+// and other keys such as structs by collector.Hash, so a custom hasher is faster for them.
+func NewConcurrentHashMapWithHasher[K comparable, V any](shards int, hasher func(key K) uint64) *ConcurrentHashMap[K, V] {
+	if shards <= 0 {
+		panic("collection: shards must be positive")
+	}
+	m := &ConcurrentHashMap[K, V]{hasher: hasher}
+	m.once.Do(func() {
+		m.init(shards)
+	})
+	return m
+}
+
+func NewConcurrentHashMapFromMap[K comparable, V any](m Map[K, V]) *ConcurrentHashMap[K, V] {
+	cm := NewConcurrentHashMap[K, V]()
+	cm.PutAll(m)
+	return cm
+}
+
+func (m *ConcurrentHashMap[K, V]) init(shards int) {
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	m.shards = make([]*concurrentShard[K, V], n)
+	for i := range m.shards {
+		m.shards[i] = &concurrentShard[K, V]{items: make(map[K]V)}
+	}
+	if m.hasher == nil {
+		m.hasher = defaultHasher[K]()
+	}
+}
+
+// shardOf returns the shard of the key, the shards are created for the zero value
+func (m *ConcurrentHashMap[K, V]) shardOf(key K) *concurrentShard[K, V] {
+	m.once.Do(func() {
+		m.init(DefaultShardCount)
+	})
+	return m.shards[m.hasher(key)&uint64(len(m.shards)-1)]
+}
+
+// allShards returns the shards, which are created for the zero value
+func (m *ConcurrentHashMap[K, V]) allShards() []*concurrentShard[K, V] {
+	m.once.Do(func() {
+		m.init(DefaultShardCount)
+	})
+	return m.shards
+}
+
+func (m *ConcurrentHashMap[K, V]) Put(key K, value V) (oldValue V, oldValueFound bool) {
+	shard := m.shardOf(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	oldValue, oldValueFound = shard.items[key]
+	shard.items[key] = value
+	if !oldValueFound {
+		m.size.Add(1)
+	}
+	return
+}
+
+func (m *ConcurrentHashMap[K, V]) PutAll(other Map[K, V]) {
+	other.ForEach(func(key K, value V) {
+		m.Put(key, value)
+	})
+}
+
+func (m *ConcurrentHashMap[K, V]) PutIfAbsent(key K, newValue V) {
+	m.PutIfAbsentAndGet(key, newValue)
+}
+
+func (m *ConcurrentHashMap[K, V]) PutIfAbsentAndGet(key K, newValue V) (oldValue V, oldValueFound bool) {
+	shard := m.shardOf(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	if oldValue, oldValueFound = shard.items[key]; !oldValueFound {
+		shard.items[key] = newValue
+		m.size.Add(1)
+	}
+	return
+}
+
+func (m *ConcurrentHashMap[K, V]) ComputeIfAbsent(key K, mapping func(key K) V) {
+	m.ComputeIfAbsentAndGet(key, mapping)
+}
+
+func (m *ConcurrentHashMap[K, V]) ComputeIfAbsentAndGet(key K, mapping func(key K) V) V {
+	// most calls find the key, which only needs the read lock
+	shard := m.shardOf(key)
+	shard.RLock()
+	value, found := shard.items[key]
+	shard.RUnlock()
+	if found {
+		return value
+	}
+
+	shard.Lock()
+	defer shard.Unlock()
+	if value, found = shard.items[key]; !found {
+		value = mapping(key)
+		shard.items[key] = value
+		m.size.Add(1)
+	}
+	return value
+}
+
+func (m *ConcurrentHashMap[K, V]) ComputeIfPresent(key K,
+	remapping func(key K, oldValue V) (newValue V, action RemappingAction),
+) {
+	m.Compute(key, func(key K, oldValue V, found bool) (V, RemappingAction) {
+		if !found {
+			return oldValue, Noop
+		}
+		return remapping(key, oldValue)
+	})
+}
+
+func (m *ConcurrentHashMap[K, V]) Compute(key K,
+	remapping func(key K, oldValue V, found bool) (newValue V, action RemappingAction),
+) (value V, found bool) {
+	shard := m.shardOf(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	oldValue, oldValueFound := shard.items[key]
+	newValue, action := remapping(key, oldValue, oldValueFound)
+	switch action {
+	case Replace:
+		shard.items[key] = newValue
+		if !oldValueFound {
+			m.size.Add(1)
+		}
+		return newValue, true
+	case Remove:
+		if oldValueFound {
+			delete(shard.items, key)
+			m.size.Add(-1)
+		}
+		return value, false
+	default:
+		return oldValue, oldValueFound
+	}
+}
+
+func (m *ConcurrentHashMap[K, V]) Merge(key K, value V,
+	remapping func(oldValue V, value V) (newValue V, action RemappingAction),
+) (newValue V, found bool) {
+	return m.Compute(key, func(_ K, oldValue V, found bool) (V, RemappingAction) {
+		if !found {
+			return value, Replace
+		}
+		return remapping(oldValue, value)
+	})
+}
+
+func (m *ConcurrentHashMap[K, V]) Get(key K) (value V, found bool) {
+	shard := m.shardOf(key)
+	shard.RLock()
+	defer shard.RUnlock()
+
+	value, found = shard.items[key]
+	return
+}
+
+func (m *ConcurrentHashMap[K, V]) GetOrDefault(key K, defaultValue V) V {
+	if value, found := m.Get(key); found {
+		return value
+	}
+	return defaultValue
+}
+
+func (m *ConcurrentHashMap[K, V]) ContainsKey(key K) bool {
+	_, found := m.Get(key)
+	return found
+}
+
+func (m *ConcurrentHashMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Size())
+	m.ForEach(func(key K, _ V) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+func (m *ConcurrentHashMap[K, V]) Values() []V {
+	values := make([]V, 0, m.Size())
+	m.ForEach(func(_ K, value V) {
+		values = append(values, value)
+	})
+	return values
+}
+
+func (m *ConcurrentHashMap[K, V]) ForEach(consumer func(key K, value V)) {
+	m.ForEachIndexed(func(_ int, key K, value V) (stop bool) {
+		consumer(key, value)
+		return false
+	})
+}
+
+func (m *ConcurrentHashMap[K, V]) ForEachIndexed(consumer func(index int, key K, value V) (stop bool)) {
+	index := 0
+	for _, shard := range m.allShards() {
+		for _, pair := range shard.snapshot() {
+			if consumer(index, pair.Key, pair.Value) {
+				return
+			}
+			index++
+		}
+	}
+}
+
+// Iterator returns a weakly consistent iterator, which copies one shard at a time,
+// Remove removes the key of the last returned pair from the map.
+func (m *ConcurrentHashMap[K, V]) Iterator() collection.Iterator[Pair[K, V]] {
+	return &concurrentMapIterator[K, V]{m: m, shards: m.allShards()}
+}
+
+func (m *ConcurrentHashMap[K, V]) Remove(key K) (value V, found bool) {
+	shard := m.shardOf(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	if value, found = shard.items[key]; found {
+		delete(shard.items, key)
+		m.size.Add(-1)
+	}
+	return
+}
+
+// RemoveIf removes all key-value pairs for which the predicate returns true,
+// the predicate is called while the shard is locked, so it must not access the map.
+func (m *ConcurrentHashMap[K, V]) RemoveIf(predicate func(key K, value V) bool) {
+	for _, shard := range m.allShards() {
+		shard.Lock()
+		for key, value := range shard.items {
+			if predicate(key, value) {
+				delete(shard.items, key)
+				m.size.Add(-1)
+			}
+		}
+		shard.Unlock()
+	}
+}
+
+func (m *ConcurrentHashMap[K, V]) Clear() {
+	for _, shard := range m.allShards() {
+		shard.Lock()
+		m.size.Add(-int64(len(shard.items)))
+		shard.items = make(map[K]V)
+		shard.Unlock()
+	}
+}
+
+func (m *ConcurrentHashMap[K, V]) IsEmpty() bool {
+	return m.Size() == 0
+}
+
+func (m *ConcurrentHashMap[K, V]) Size() int {
+	return int(m.size.Load())
+}
+
+func (m *ConcurrentHashMap[K, V]) AsBuiltinMap() map[K]V {
+	builtinMap := make(map[K]V, m.Size())
+	m.ForEach(func(key K, value V) {
+		builtinMap[key] = value
+	})
+	return builtinMap
+}
+
+func (m *ConcurrentHashMap[K, V]) String() string {
+	return MapString[K, V](m)
+}
+
+func (m *ConcurrentHashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return MarshalJSON[K, V](m)
+}
+
+func (m *ConcurrentHashMap[K, V]) UnmarshalJSON(bytes []byte) error {
+	return UnmarshalJSON[K, V](m, bytes)
+}
+
+// snapshot copies the key-value pairs of the shard
+func (s *concurrentShard[K, V]) snapshot() []Pair[K, V] {
+	s.RLock()
+	defer s.RUnlock()
+
+	pairs := make([]Pair[K, V], 0, len(s.items))
+	for key, value := range s.items {
+		pairs = append(pairs, Pair[K, V]{Key: key, Value: value})
+	}
+	return pairs
+}
+
+type concurrentMapIterator[K comparable, V any] struct {
+	m      *ConcurrentHashMap[K, V]
+	shards []*concurrentShard[K, V]
+	pairs  []Pair[K, V]
+	index  int
+	last   *Pair[K, V]
+}
+
+func (it *concurrentMapIterator[K, V]) Next() (pair Pair[K, V], ok bool) {
+	for it.index >= len(it.pairs) {
+		if len(it.shards) == 0 {
+			it.last = nil
+			return pair, false
+		}
+		it.pairs, it.index = it.shards[0].snapshot(), 0
+		it.shards = it.shards[1:]
+	}
+	it.last = &it.pairs[it.index]
+	it.index++
+	return *it.last, true
+}
+
+func (it *concurrentMapIterator[K, V]) Remove() {
+	if it.last == nil {
+		panic("collection: Remove must be called after Next")
+	}
+	it.m.Remove(it.last.Key)
+	it.last = nil
+}
+
+// defaultHasher returns a hasher with a random seed, which hashes the keys by their kinds
+func defaultHasher[K comparable]() func(key K) uint64 {
+	seed := maphash.MakeSeed()
+	hashString := func(s string) uint64 {
+		var h maphash.Hash
+		h.SetSeed(seed)
+		_, _ = h.WriteString(s)
+		return h.Sum64()
+	}
+	return func(key K) uint64 {
+		switch k := any(key).(type) {
+		case string:
+			return hashString(k)
+		case int:
+			return spread(uint64(k))
+		case int64:
+			return spread(uint64(k))
+		case uint64:
+			return spread(k)
+		}
+
+		v := reflect.ValueOf(key)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return spread(uint64(v.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return spread(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			if f == 0 {
+				// -0 equals to +0
+				f = 0
+			}
+			return spread(math.Float64bits(f))
+		case reflect.Bool:
+			if v.Bool() {
+				return spread(1)
+			}
+			return spread(0)
+		case reflect.String:
+			return hashString(v.String())
+		case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+			return spread(uint64(v.Pointer()))
+		case reflect.Invalid:
+			// the nil interface
+			return 0
+		default:
+			return collector.Hash(key)
+		}
+	}
+}
+
+// spread is the finalizer of SplitMix64, which spreads the bits of the key over all 64 bits
+func spread(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
@@ -0,0 +1,175 @@
+package _map
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"math"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentHashMap_Put(t *testing.T) {
+	var m ConcurrentMap[string, int] = NewConcurrentHashMap[string, int]()
+	_, found := m.Put("a", 1)
+	require.False(t, found)
+	old, found := m.Put("a", 2)
+	require.True(t, found)
+	require.Equal(t, 1, old)
+
+	old, found = m.PutIfAbsentAndGet("a", 3)
+	require.True(t, found)
+	require.Equal(t, 2, old)
+	_, found = m.PutIfAbsentAndGet("b", 3)
+	require.False(t, found)
+	require.Equal(t, map[string]int{"a": 2, "b": 3}, m.AsBuiltinMap())
+
+	value, _ := m.Remove("a")
+	require.Equal(t, 2, value)
+	require.Equal(t, 1, m.Size())
+	m.Clear()
+	require.True(t, m.IsEmpty())
+}
+
+func TestConcurrentHashMap_Compute(t *testing.T) {
+	m := NewConcurrentHashMap[string, int]()
+	require.Equal(t, 1, m.ComputeIfAbsentAndGet("a", func(key string) int {
+		return 1
+	}))
+	require.Equal(t, 1, m.ComputeIfAbsentAndGet("a", func(key string) int {
+		panic("the key exists")
+	}))
+
+	value, found := m.Compute("b", func(key string, oldValue int, found bool) (int, RemappingAction) {
+		require.False(t, found)
+		return 10, Replace
+	})
+	require.True(t, found)
+	require.Equal(t, 10, value)
+	_, found = m.Compute("b", func(key string, oldValue int, found bool) (int, RemappingAction) {
+		return 0, Remove
+	})
+	require.False(t, found)
+	_, found = m.Compute("c", func(key string, oldValue int, found bool) (int, RemappingAction) {
+		return 0, Noop
+	})
+	require.False(t, found)
+
+	m.ComputeIfPresent("a", func(key string, oldValue int) (int, RemappingAction) {
+		return oldValue + 1, Replace
+	})
+	m.ComputeIfPresent("c", func(key string, oldValue int) (int, RemappingAction) {
+		panic("the key does not exist")
+	})
+	require.Equal(t, map[string]int{"a": 2}, m.AsBuiltinMap())
+
+	sum := func(oldValue int, value int) (int, RemappingAction) {
+		return oldValue + value, Replace
+	}
+	value, _ = m.Merge("a", 3, sum)
+	require.Equal(t, 5, value)
+	value, _ = m.Merge("d", 3, sum)
+	require.Equal(t, 3, value)
+	require.Equal(t, 2, m.Size())
+}
+
+func TestConcurrentHashMap_Concurrency(t *testing.T) {
+	m := NewConcurrentHashMapWithShards[int, int](4)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				m.Merge(j%100, 1, func(oldValue int, value int) (int, RemappingAction) {
+					return oldValue + value, Replace
+				})
+				m.PutIfAbsent(1000+worker, worker)
+				// the readers never block the writers
+				m.ForEach(func(key int, value int) {
+					if key == j {
+						m.Remove(-1)
+					}
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 108, m.Size())
+	for key := 0; key < 100; key++ {
+		value, _ := m.Get(key)
+		require.Equal(t, 80, value)
+	}
+	for worker := 0; worker < 8; worker++ {
+		require.Equal(t, worker, m.GetOrDefault(1000+worker, -1))
+	}
+}
+
+func TestConcurrentHashMap_Iterator(t *testing.T) {
+	var m ConcurrentHashMap[int, int]
+	for i := 0; i < 100; i++ {
+		m.Put(i, i*i)
+	}
+	it := m.Iterator()
+	count := 0
+	for pair, ok := it.Next(); ok; pair, ok = it.Next() {
+		require.Equal(t, pair.Key*pair.Key, pair.Value)
+		if pair.Key%2 == 0 {
+			it.Remove()
+		}
+		count++
+	}
+	require.Equal(t, 100, count)
+	require.Equal(t, 50, m.Size())
+
+	m.RemoveIf(func(key int, value int) bool {
+		return key > 10
+	})
+	keys := m.Keys()
+	sort.Ints(keys)
+	require.Equal(t, []int{1, 3, 5, 7, 9}, keys)
+	require.Panics(t, func() {
+		m.Iterator().Remove()
+	})
+}
+
+func TestConcurrentHashMap_Hasher(t *testing.T) {
+	type point struct {
+		x, y int
+	}
+	m := NewConcurrentHashMap[point, string]()
+	m.Put(point{1, 2}, "a")
+	m.Put(point{1, 2}, "b")
+	require.Equal(t, 1, m.Size())
+
+	floats := NewConcurrentHashMapWithHasher[float64, int](8, nil)
+	floats.Put(0.0, 1)
+	// -0 equals to +0, so they must be hashed to the same shard
+	floats.Put(math.Copysign(0, -1), 2)
+	require.Equal(t, 1, floats.Size())
+	require.Equal(t, 2, floats.GetOrDefault(0, -1))
+
+	custom := NewConcurrentHashMapWithHasher[point, int](2, func(p point) uint64 {
+		return uint64(p.x)
+	})
+	custom.Put(point{1, 2}, 1)
+	custom.Put(point{2, 1}, 2)
+	require.Equal(t, 2, custom.GetOrDefault(point{2, 1}, 0))
+	require.Panics(t, func() {
+		NewConcurrentHashMapWithShards[int, int](0)
+	})
+}
+
+func TestConcurrentHashMap_JSON(t *testing.T) {
+	m := NewConcurrentHashMap[string, int]()
+	m.Put("a", 1)
+	bz, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1}`, string(bz))
+	require.Equal(t, "{a: 1}", m.String())
+
+	var m2 ConcurrentHashMap[string, int]
+	require.NoError(t, json.Unmarshal([]byte(`{"b":2,"c":3}`), &m2))
+	require.Equal(t, map[string]int{"b": 2, "c": 3}, m2.AsBuiltinMap())
+}
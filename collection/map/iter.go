@@ -0,0 +1,39 @@
+//go:build go1.23
+
+package _map
+
+import "iter"
+
+// All returns an iter.Seq2 over the key-value pairs of the map
+func All[K comparable, V any](m Map[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		it := m.Iterator()
+		for pair, ok := it.Next(); ok; pair, ok = it.Next() {
+			if !yield(pair.Key, pair.Value) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iter.Seq over the keys of the map
+func KeysSeq[K comparable, V any](m Map[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range All(m) {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iter.Seq over the values of the map
+func ValuesSeq[K comparable, V any](m Map[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range All(m) {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+//go:build go1.23
+
+package _map
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	m := NewLinkedHashMap[string, int]()
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+
+	var keys []string
+	var values []int
+	for key, value := range All[string, int](m) {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	require.Equal(t, []string{"a", "b", "c"}, keys)
+	require.Equal(t, []int{1, 2, 3}, values)
+
+	keys = keys[:0]
+	for key := range KeysSeq[string, int](m) {
+		if key == "c" {
+			break
+		}
+		keys = append(keys, key)
+	}
+	require.Equal(t, []string{"a", "b"}, keys)
+
+	values = values[:0]
+	for value := range ValuesSeq[string, int](m) {
+		values = append(values, value)
+	}
+	require.Equal(t, []int{1, 2, 3}, values)
+}
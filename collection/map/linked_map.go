@@ -1,26 +1,44 @@
 package _map
 
-import "container/list"
+import (
+	"container/list"
+	"github.com/carter-ya/go-tools/collection"
+)
 
+// LinkedHashMap is a HashMap which keeps its keys in insertion order, or in access order,
+// see NewLinkedHashMapWithAccessOrder.
 type LinkedHashMap[K comparable, V any] struct {
 	hashMap HashMap[K, V]
 	list    *list.List
+	// elements are the elements of the keys in the list
+	elements    map[K]*list.Element
+	accessOrder bool
+	// modCount is the number of the keys added, removed and moved, so that the iterators can detect the modifications
+	modCount int
 }
 
 func NewLinkedHashMap[K comparable, V any]() *LinkedHashMap[K, V] {
-	return &LinkedHashMap[K, V]{
-		hashMap: NewHashMap[K, V](),
-		list:    list.New(),
-	}
+	return NewLinkedHashMapWithSize[K, V](0)
 }
 
 func NewLinkedHashMapWithSize[K comparable, V any](size int) *LinkedHashMap[K, V] {
 	return &LinkedHashMap[K, V]{
-		hashMap: NewHashMapWithSize[K, V](size),
-		list:    list.New(),
+		hashMap:  NewHashMapWithSize[K, V](size),
+		list:     list.New(),
+		elements: make(map[K]*list.Element, size),
 	}
 }
 
+// NewLinkedHashMapWithAccessOrder creates a LinkedHashMap whose keys are in the order
+// from the least recently accessed to the most recently accessed.
+// Get, GetOrDefault, and replacing the value of a key by Put or ComputeIfPresent access the key,
+// so the eldest key is the least recently used one, see Eldest and RemoveEldest.
+func NewLinkedHashMapWithAccessOrder[K comparable, V any](size int) *LinkedHashMap[K, V] {
+	m := NewLinkedHashMapWithSize[K, V](size)
+	m.accessOrder = true
+	return m
+}
+
 func NewLinkedHashMapFromMap[K comparable, V any](m Map[K, V]) *LinkedHashMap[K, V] {
 	lm := NewLinkedHashMapWithSize[K, V](m.Size())
 	lm.PutAll(m)
@@ -30,7 +48,10 @@ func NewLinkedHashMapFromMap[K comparable, V any](m Map[K, V]) *LinkedHashMap[K,
 func (m *LinkedHashMap[K, V]) Put(key K, value V) (oldValue V, oldValueFound bool) {
 	oldValue, oldValueFound = m.hashMap.Put(key, value)
 	if !oldValueFound {
-		m.list.PushBack(key)
+		m.elements[key] = m.list.PushBack(key)
+		m.modCount++
+	} else {
+		m.access(key)
 	}
 	return
 }
@@ -61,6 +82,7 @@ func (m *LinkedHashMap[K, V]) ComputeIfPresent(key K,
 		switch action {
 		case Replace:
 			m.hashMap.Put(key, newValue)
+			m.access(key)
 		case Remove:
 			m.Remove(key)
 		}
@@ -68,11 +90,36 @@ func (m *LinkedHashMap[K, V]) ComputeIfPresent(key K,
 }
 
 func (m *LinkedHashMap[K, V]) Get(key K) (value V, found bool) {
-	return m.hashMap.Get(key)
+	if value, found = m.hashMap.Get(key); found {
+		m.access(key)
+	}
+	return
 }
 
 func (m *LinkedHashMap[K, V]) GetOrDefault(key K, defaultValue V) V {
-	return m.hashMap.GetOrDefault(key, defaultValue)
+	if value, found := m.Get(key); found {
+		return value
+	}
+	return defaultValue
+}
+
+// Eldest returns the first key in the order of the map,
+// which is the least recently used key if the map is in access order.
+func (m *LinkedHashMap[K, V]) Eldest() (key K, value V, found bool) {
+	e := m.list.Front()
+	if e == nil {
+		return key, value, false
+	}
+	key = e.Value.(K)
+	return key, m.hashMap[key], true
+}
+
+// RemoveEldest removes the first key in the order of the map, see Eldest.
+func (m *LinkedHashMap[K, V]) RemoveEldest() (key K, value V, found bool) {
+	if key, value, found = m.Eldest(); found {
+		m.Remove(key)
+	}
+	return
 }
 
 func (m *LinkedHashMap[K, V]) ContainsKey(key K) bool {
@@ -111,31 +158,39 @@ func (m *LinkedHashMap[K, V]) ForEachIndexed(consumer func(index int, key K, val
 	}
 }
 
+// Iterator returns an iterator over the key-value pairs in the order of the map.
+// Next and Remove panic if a key is added, removed or accessed in access order other than by the iterator.
+func (m *LinkedHashMap[K, V]) Iterator() collection.Iterator[Pair[K, V]] {
+	return &linkedHashMapIterator[K, V]{m: m, next: m.list.Front(), modCount: m.modCount}
+}
+
 func (m *LinkedHashMap[K, V]) Remove(key K) (oldValue V, oldValueFound bool) {
 	oldValue, oldValueFound = m.hashMap.Remove(key)
 	if oldValueFound {
-		for e := m.list.Front(); e != nil; e = e.Next() {
-			if e.Value == key {
-				m.list.Remove(e)
-				break
-			}
-		}
+		m.list.Remove(m.elements[key])
+		delete(m.elements, key)
+		m.modCount++
 	}
 	return
 }
 
 func (m *LinkedHashMap[K, V]) RemoveIf(predicate func(key K, value V) bool) {
-	for e := m.list.Front(); e != nil; e = e.Next() {
+	for e := m.list.Front(); e != nil; {
+		// the next element is taken before e is removed
+		next := e.Next()
 		key := e.Value.(K)
 		if predicate(key, m.hashMap[key]) {
 			m.Remove(key)
 		}
+		e = next
 	}
 }
 
 func (m *LinkedHashMap[K, V]) Clear() {
 	m.hashMap.Clear()
 	m.list.Init()
+	m.elements = make(map[K]*list.Element)
+	m.modCount++
 }
 
 func (m *LinkedHashMap[K, V]) IsEmpty() bool {
@@ -159,7 +214,55 @@ func (m *LinkedHashMap[K, V]) MarshalJSON() ([]byte, error) {
 }
 
 func (m *LinkedHashMap[K, V]) UnmarshalJSON(bytes []byte) error {
+	if m.list != nil && m.list.Len() > 0 {
+		// the existing keys are removed
+		m.modCount++
+	}
 	m.hashMap = NewHashMap[K, V]()
 	m.list = list.New()
+	m.elements = make(map[K]*list.Element)
 	return UnmarshalJSON[K, V](m, bytes)
 }
+
+// access moves the key to the back if the map is in access order
+func (m *LinkedHashMap[K, V]) access(key K) {
+	if e := m.elements[key]; m.accessOrder && e != m.list.Back() {
+		m.list.MoveToBack(e)
+		m.modCount++
+	}
+}
+
+// linkedHashMapIterator iterates over the linked list in insertion order
+type linkedHashMapIterator[K comparable, V any] struct {
+	m    *LinkedHashMap[K, V]
+	next *list.Element
+	last *list.Element
+	// modCount is the expected modCount of the map
+	modCount int
+}
+
+func (it *linkedHashMapIterator[K, V]) checkModification() {
+	if it.modCount != it.m.modCount {
+		panic("collection: the map is modified during the iteration")
+	}
+}
+
+func (it *linkedHashMapIterator[K, V]) Next() (pair Pair[K, V], ok bool) {
+	it.checkModification()
+	if it.next == nil {
+		return pair, false
+	}
+	it.last, it.next = it.next, it.next.Next()
+	key := it.last.Value.(K)
+	return Pair[K, V]{Key: key, Value: it.m.hashMap[key]}, true
+}
+
+func (it *linkedHashMapIterator[K, V]) Remove() {
+	if it.last == nil {
+		panic("collection: Remove must be called after Next")
+	}
+	it.checkModification()
+	it.m.Remove(it.last.Value.(K))
+	it.modCount = it.m.modCount
+	it.last = nil
+}
@@ -29,6 +29,92 @@ func TestLinkedHashMap_Keys(t *testing.T) {
 	require.Equal(t, 0, m.Size())
 }
 
+func TestLinkedHashMap_Iterator(t *testing.T) {
+	var m Map[string, int] = NewLinkedHashMap[string, int]()
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+
+	it := m.Iterator()
+	var pairs []Pair[string, int]
+	for pair, ok := it.Next(); ok; pair, ok = it.Next() {
+		pairs = append(pairs, pair)
+		if pair.Key == "b" {
+			it.Remove()
+		}
+	}
+	require.Equal(t, []Pair[string, int]{{"a", 1}, {"b", 2}, {"c", 3}}, pairs)
+	require.Equal(t, []string{"a", "c"}, m.Keys())
+
+	// the keys added or removed other than by the iterator fail the iteration
+	it = m.Iterator()
+	_, ok := it.Next()
+	require.True(t, ok)
+	m.Remove("c")
+	require.Panics(t, func() {
+		it.Next()
+	})
+	require.Panics(t, func() {
+		it.Remove()
+	})
+	it = m.Iterator()
+	m.Put("d", 4)
+	require.Panics(t, func() {
+		it.Next()
+	})
+	m.Put("d", 5)
+	it = m.Iterator()
+	m.Put("d", 6)
+	pair, ok := it.Next()
+	require.True(t, ok)
+	require.Equal(t, Pair[string, int]{"a", 1}, pair)
+}
+
+func TestLinkedHashMap_AccessOrder(t *testing.T) {
+	m := NewLinkedHashMapWithAccessOrder[string, int](0)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+	m.Get("a")
+	m.Put("b", 20)
+	require.Equal(t, []string{"c", "a", "b"}, m.Keys())
+	require.True(t, m.ContainsKey("c"))
+	require.Equal(t, []string{"c", "a", "b"}, m.Keys())
+
+	key, value, found := m.Eldest()
+	require.True(t, found)
+	require.Equal(t, "c", key)
+	require.Equal(t, 3, value)
+	key, _, found = m.RemoveEldest()
+	require.True(t, found)
+	require.Equal(t, "c", key)
+	require.Equal(t, []string{"a", "b"}, m.Keys())
+
+	// an access during the iteration is a modification in access order
+	it := m.Iterator()
+	it.Next()
+	m.Get("a")
+	require.Panics(t, func() {
+		it.Next()
+	})
+
+	m.Clear()
+	_, _, found = m.RemoveEldest()
+	require.False(t, found)
+
+	// the insertion order is kept by the accesses of a map in insertion order
+	m2 := NewLinkedHashMap[string, int]()
+	m2.Put("a", 1)
+	m2.Put("b", 2)
+	m2.Get("a")
+	m2.Put("a", 10)
+	require.Equal(t, []string{"a", "b"}, m2.Keys())
+	m2.RemoveIf(func(key string, value int) bool {
+		return true
+	})
+	require.True(t, m2.IsEmpty())
+}
+
 func TestLinkedHashMap_String(t *testing.T) {
 	var m Map[string, int] = NewHashMap[string, int]()
 	m.Put("a", 1)
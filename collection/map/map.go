@@ -3,6 +3,8 @@ package _map
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/carter-ya/go-tools/collection"
+	"reflect"
 )
 
 type RemappingAction int
@@ -49,6 +51,8 @@ type Map[K comparable, V any] interface {
 	// ForEachIndexed iterates over all key-value pairs in the map.
 	// The consumer function returns true to stop iterating.
 	ForEachIndexed(consumer func(index int, key K, value V) (stop bool))
+	// Iterator returns an iterator over all key-value pairs in the map.
+	Iterator() collection.Iterator[Pair[K, V]]
 
 	// Remove removes the key-value pair with the given key from the map. If the key exists, the value is returned.
 	Remove(key K) (value V, found bool)
@@ -134,6 +138,16 @@ func (m HashMap[K, V]) ForEachIndexed(consumer func(index int, key K, value V) (
 	ForEachIndexed(m, consumer)
 }
 
+// Iterator returns an iterator over the key-value pairs, which iterates lazily like a range loop,
+// so the pairs removed during the iteration are not returned, and the pairs added may or may not be returned.
+// Remove removes the key of the last returned pair from the map.
+func (m HashMap[K, V]) Iterator() collection.Iterator[Pair[K, V]] {
+	it := &hashMapIterator[K, V]{m: m, iter: reflect.ValueOf(m).MapRange()}
+	it.keyValue = reflect.ValueOf(&it.key).Elem()
+	it.valueValue = reflect.ValueOf(&it.value).Elem()
+	return it
+}
+
 func (m HashMap[K, V]) ContainsKey(key K) bool {
 	_, found := m[key]
 	return found
@@ -187,3 +201,34 @@ func (m HashMap[K, V]) MarshalJSON() ([]byte, error) {
 func (m HashMap[K, V]) UnmarshalJSON(bytes []byte) error {
 	return json.Unmarshal(bytes, &m)
 }
+
+// hashMapIterator iterates over a HashMap by a reflect.MapIter,
+// the current pair is copied into key and value without allocations.
+type hashMapIterator[K comparable, V any] struct {
+	m          HashMap[K, V]
+	iter       *reflect.MapIter
+	key        K
+	value      V
+	keyValue   reflect.Value
+	valueValue reflect.Value
+	// last is true if Next has returned a pair which is not removed
+	last bool
+}
+
+func (it *hashMapIterator[K, V]) Next() (pair Pair[K, V], ok bool) {
+	if !it.iter.Next() {
+		return pair, false
+	}
+	it.keyValue.SetIterKey(it.iter)
+	it.valueValue.SetIterValue(it.iter)
+	it.last = true
+	return Pair[K, V]{Key: it.key, Value: it.value}, true
+}
+
+func (it *hashMapIterator[K, V]) Remove() {
+	if !it.last {
+		panic("collection: Remove must be called after Next")
+	}
+	delete(it.m, it.key)
+	it.last = false
+}
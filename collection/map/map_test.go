@@ -102,3 +102,30 @@ func TestHashMap_Clear(t *testing.T) {
 	m.Clear()
 	require.Equal(t, 0, m.Size())
 }
+
+func TestHashMap_Iterator(t *testing.T) {
+	m := NewHashMapFromBuiltinMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	it := m.Iterator()
+	var pairs []Pair[string, int]
+	for pair, ok := it.Next(); ok; pair, ok = it.Next() {
+		pairs = append(pairs, pair)
+		if pair.Value > 1 {
+			it.Remove()
+		}
+	}
+	require.ElementsMatch(t, []Pair[string, int]{{"a", 1}, {"b", 2}, {"c", 3}}, pairs)
+	require.Equal(t, map[string]int{"a": 1}, m.AsBuiltinMap())
+
+	// the iteration is lazy, so the pairs removed after the first Next are not returned
+	m = NewHashMapFromBuiltinMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	it = m.Iterator()
+	first, ok := it.Next()
+	require.True(t, ok)
+	for _, key := range m.Keys() {
+		if key != first.Key {
+			m.Remove(key)
+		}
+	}
+	_, ok = it.Next()
+	require.False(t, ok)
+}
@@ -0,0 +1,377 @@
+package _map
+
+const (
+	red   = false
+	black = true
+)
+
+// treeNode is a node of redBlackTree, the nil node is black
+type treeNode[K any, V any] struct {
+	key    K
+	value  V
+	left   *treeNode[K, V]
+	right  *treeNode[K, V]
+	parent *treeNode[K, V]
+	color  bool
+}
+
+// redBlackTree is a binary search tree ordered by compare, which keeps balanced by coloring the nodes,
+// the algorithms follow "Introduction to Algorithms".
+type redBlackTree[K any, V any] struct {
+	root    *treeNode[K, V]
+	size    int
+	compare func(a, b K) int
+}
+
+func newRedBlackTree[K any, V any](compare func(a, b K) int) *redBlackTree[K, V] {
+	return &redBlackTree[K, V]{compare: compare}
+}
+
+// get returns the node of the key, or nil if the key does not exist
+func (t *redBlackTree[K, V]) get(key K) *treeNode[K, V] {
+	n := t.root
+	for n != nil {
+		c := t.compare(key, n.key)
+		switch {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// put inserts the key-value pair, or replaces the value if the key exists
+func (t *redBlackTree[K, V]) put(key K, value V) (oldValue V, oldValueFound bool) {
+	var parent *treeNode[K, V]
+	c := 0
+	for n := t.root; n != nil; {
+		parent = n
+		c = t.compare(key, n.key)
+		switch {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			oldValue, n.value = n.value, value
+			return oldValue, true
+		}
+	}
+
+	n := &treeNode[K, V]{key: key, value: value, parent: parent, color: red}
+	switch {
+	case parent == nil:
+		t.root = n
+	case c < 0:
+		parent.left = n
+	default:
+		parent.right = n
+	}
+	t.size++
+	t.fixAfterInsertion(n)
+	return oldValue, false
+}
+
+// delete removes the node, if the node has two children,
+// the key-value pair of its successor is moved into it and the successor node is removed instead.
+func (t *redBlackTree[K, V]) delete(n *treeNode[K, V]) {
+	t.size--
+	if n.left != nil && n.right != nil {
+		s := successor(n)
+		n.key, n.value = s.key, s.value
+		n = s
+	}
+
+	replacement := n.left
+	if replacement == nil {
+		replacement = n.right
+	}
+	if replacement != nil {
+		replacement.parent = n.parent
+		t.replaceChild(n, replacement)
+		n.left, n.right, n.parent = nil, nil, nil
+		if n.color == black {
+			t.fixAfterDeletion(replacement)
+		}
+		return
+	}
+	if n.parent == nil {
+		t.root = nil
+		return
+	}
+	if n.color == black {
+		t.fixAfterDeletion(n)
+	}
+	if n.parent != nil {
+		t.replaceChild(n, nil)
+		n.parent = nil
+	}
+}
+
+func (t *redBlackTree[K, V]) clear() {
+	t.root = nil
+	t.size = 0
+}
+
+func (t *redBlackTree[K, V]) first() *treeNode[K, V] {
+	n := t.root
+	if n != nil {
+		for n.left != nil {
+			n = n.left
+		}
+	}
+	return n
+}
+
+func (t *redBlackTree[K, V]) last() *treeNode[K, V] {
+	n := t.root
+	if n != nil {
+		for n.right != nil {
+			n = n.right
+		}
+	}
+	return n
+}
+
+// ceiling returns the node with the least key greater than or equal to the key,
+// or strictly greater than the key if inclusive is false.
+func (t *redBlackTree[K, V]) ceiling(key K, inclusive bool) *treeNode[K, V] {
+	var candidate *treeNode[K, V]
+	for n := t.root; n != nil; {
+		c := t.compare(key, n.key)
+		if c < 0 || (c == 0 && inclusive) {
+			if c == 0 {
+				return n
+			}
+			candidate = n
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return candidate
+}
+
+// floor returns the node with the greatest key less than or equal to the key,
+// or strictly less than the key if inclusive is false.
+func (t *redBlackTree[K, V]) floor(key K, inclusive bool) *treeNode[K, V] {
+	var candidate *treeNode[K, V]
+	for n := t.root; n != nil; {
+		c := t.compare(key, n.key)
+		if c > 0 || (c == 0 && inclusive) {
+			if c == 0 {
+				return n
+			}
+			candidate = n
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return candidate
+}
+
+func successor[K any, V any](n *treeNode[K, V]) *treeNode[K, V] {
+	if n.right != nil {
+		n = n.right
+		for n.left != nil {
+			n = n.left
+		}
+		return n
+	}
+	p := n.parent
+	for p != nil && n == p.right {
+		n, p = p, p.parent
+	}
+	return p
+}
+
+func predecessor[K any, V any](n *treeNode[K, V]) *treeNode[K, V] {
+	if n.left != nil {
+		n = n.left
+		for n.right != nil {
+			n = n.right
+		}
+		return n
+	}
+	p := n.parent
+	for p != nil && n == p.left {
+		n, p = p, p.parent
+	}
+	return p
+}
+
+func colorOf[K any, V any](n *treeNode[K, V]) bool {
+	if n == nil {
+		return black
+	}
+	return n.color
+}
+
+func setColor[K any, V any](n *treeNode[K, V], color bool) {
+	if n != nil {
+		n.color = color
+	}
+}
+
+func parentOf[K any, V any](n *treeNode[K, V]) *treeNode[K, V] {
+	if n == nil {
+		return nil
+	}
+	return n.parent
+}
+
+func leftOf[K any, V any](n *treeNode[K, V]) *treeNode[K, V] {
+	if n == nil {
+		return nil
+	}
+	return n.left
+}
+
+func rightOf[K any, V any](n *treeNode[K, V]) *treeNode[K, V] {
+	if n == nil {
+		return nil
+	}
+	return n.right
+}
+
+// replaceChild replaces the node n by the child in the parent of n
+func (t *redBlackTree[K, V]) replaceChild(n, child *treeNode[K, V]) {
+	switch {
+	case n.parent == nil:
+		t.root = child
+	case n == n.parent.left:
+		n.parent.left = child
+	default:
+		n.parent.right = child
+	}
+}
+
+func (t *redBlackTree[K, V]) rotateLeft(n *treeNode[K, V]) {
+	if n == nil {
+		return
+	}
+	r := n.right
+	n.right = r.left
+	if r.left != nil {
+		r.left.parent = n
+	}
+	r.parent = n.parent
+	t.replaceChild(n, r)
+	r.left = n
+	n.parent = r
+}
+
+func (t *redBlackTree[K, V]) rotateRight(n *treeNode[K, V]) {
+	if n == nil {
+		return
+	}
+	l := n.left
+	n.left = l.right
+	if l.right != nil {
+		l.right.parent = n
+	}
+	l.parent = n.parent
+	t.replaceChild(n, l)
+	l.right = n
+	n.parent = l
+}
+
+func (t *redBlackTree[K, V]) fixAfterInsertion(x *treeNode[K, V]) {
+	x.color = red
+	for x != nil && x != t.root && x.parent.color == red {
+		if parentOf(x) == leftOf(parentOf(parentOf(x))) {
+			y := rightOf(parentOf(parentOf(x)))
+			if colorOf(y) == red {
+				setColor(parentOf(x), black)
+				setColor(y, black)
+				setColor(parentOf(parentOf(x)), red)
+				x = parentOf(parentOf(x))
+			} else {
+				if x == rightOf(parentOf(x)) {
+					x = parentOf(x)
+					t.rotateLeft(x)
+				}
+				setColor(parentOf(x), black)
+				setColor(parentOf(parentOf(x)), red)
+				t.rotateRight(parentOf(parentOf(x)))
+			}
+		} else {
+			y := leftOf(parentOf(parentOf(x)))
+			if colorOf(y) == red {
+				setColor(parentOf(x), black)
+				setColor(y, black)
+				setColor(parentOf(parentOf(x)), red)
+				x = parentOf(parentOf(x))
+			} else {
+				if x == leftOf(parentOf(x)) {
+					x = parentOf(x)
+					t.rotateRight(x)
+				}
+				setColor(parentOf(x), black)
+				setColor(parentOf(parentOf(x)), red)
+				t.rotateLeft(parentOf(parentOf(x)))
+			}
+		}
+	}
+	t.root.color = black
+}
+
+func (t *redBlackTree[K, V]) fixAfterDeletion(x *treeNode[K, V]) {
+	for x != t.root && colorOf(x) == black {
+		if x == leftOf(parentOf(x)) {
+			sib := rightOf(parentOf(x))
+			if colorOf(sib) == red {
+				setColor(sib, black)
+				setColor(parentOf(x), red)
+				t.rotateLeft(parentOf(x))
+				sib = rightOf(parentOf(x))
+			}
+			if colorOf(leftOf(sib)) == black && colorOf(rightOf(sib)) == black {
+				setColor(sib, red)
+				x = parentOf(x)
+			} else {
+				if colorOf(rightOf(sib)) == black {
+					setColor(leftOf(sib), black)
+					setColor(sib, red)
+					t.rotateRight(sib)
+					sib = rightOf(parentOf(x))
+				}
+				setColor(sib, colorOf(parentOf(x)))
+				setColor(parentOf(x), black)
+				setColor(rightOf(sib), black)
+				t.rotateLeft(parentOf(x))
+				x = t.root
+			}
+		} else {
+			sib := leftOf(parentOf(x))
+			if colorOf(sib) == red {
+				setColor(sib, black)
+				setColor(parentOf(x), red)
+				t.rotateRight(parentOf(x))
+				sib = leftOf(parentOf(x))
+			}
+			if colorOf(rightOf(sib)) == black && colorOf(leftOf(sib)) == black {
+				setColor(sib, red)
+				x = parentOf(x)
+			} else {
+				if colorOf(leftOf(sib)) == black {
+					setColor(rightOf(sib), black)
+					setColor(sib, red)
+					t.rotateLeft(sib)
+					sib = leftOf(parentOf(x))
+				}
+				setColor(sib, colorOf(parentOf(x)))
+				setColor(parentOf(x), black)
+				setColor(leftOf(sib), black)
+				t.rotateRight(parentOf(x))
+				x = t.root
+			}
+		}
+	}
+	setColor(x, black)
+}
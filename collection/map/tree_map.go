@@ -0,0 +1,494 @@
+package _map
+
+import (
+	"fmt"
+	"github.com/carter-ya/go-tools/collection"
+	"golang.org/x/exp/constraints"
+	"reflect"
+)
+
+// NavigableMap is a Map sorted by keys, which can be navigated by key and viewed by key ranges
+type NavigableMap[K comparable, V any] interface {
+	Map[K, V]
+
+	// FirstKey returns the least key
+	FirstKey() (key K, found bool)
+	// LastKey returns the greatest key
+	LastKey() (key K, found bool)
+	// Floor returns the key-value pair with the greatest key less than or equal to the given key
+	Floor(key K) (pair Pair[K, V], found bool)
+	// Ceiling returns the key-value pair with the least key greater than or equal to the given key
+	Ceiling(key K) (pair Pair[K, V], found bool)
+	// Lower returns the key-value pair with the greatest key strictly less than the given key
+	Lower(key K) (pair Pair[K, V], found bool)
+	// Higher returns the key-value pair with the least key strictly greater than the given key
+	Higher(key K) (pair Pair[K, V], found bool)
+
+	// HeadMap returns a view of the key-value pairs whose keys are less than (or equal to, if inclusive) toKey.
+	HeadMap(toKey K, inclusive bool) NavigableMap[K, V]
+	// TailMap returns a view of the key-value pairs whose keys are greater than (or equal to, if inclusive) fromKey.
+	TailMap(fromKey K, inclusive bool) NavigableMap[K, V]
+	// SubMap returns a view of the key-value pairs whose keys range from fromKey to toKey.
+	SubMap(fromKey K, fromInclusive bool, toKey K, toInclusive bool) NavigableMap[K, V]
+
+	// Comparator returns the function which orders the keys
+	Comparator() func(a, b K) int
+	// DescendingKeys returns the keys in descending order
+	DescendingKeys() []K
+	// DescendingIterator returns an iterator over the key-value pairs in descending order of keys
+	DescendingIterator() collection.Iterator[Pair[K, V]]
+}
+
+// TreeMap is a NavigableMap based on a red-black tree,
+// Put, Get and Remove take O(log n) time, and the keys are iterated in ascending order.
+//
+// The views returned by HeadMap, TailMap and SubMap share the tree with the map,
+// so the changes of a view are visible in the map and vice versa.
+// Putting a key out of the range of a view panics.
+//
+// The zero value is an empty map ordered by the natural order of the keys,
+// whose kind must be an integer, a float or a string.
+//
+// Note: TreeMap is not routine-safe.
+type TreeMap[K comparable, V any] struct {
+	tree *redBlackTree[K, V]
+	// from and to bound the keys of a view, nil means unbounded
+	from *treeBound[K]
+	to   *treeBound[K]
+}
+
+var _ NavigableMap[int, any] = (*TreeMap[int, any])(nil)
+
+type treeBound[K any] struct {
+	key       K
+	inclusive bool
+}
+
+// NewTreeMap returns a TreeMap ordered by the natural order of the keys
+func NewTreeMap[K constraints.Ordered, V any]() *TreeMap[K, V] {
+	return NewTreeMapWithComparator[K, V](compareOrdered[K])
+}
+
+// NewTreeMapWithComparator returns a TreeMap ordered by compare,
+// which returns a negative number if a < b, zero if a == b and a positive number if a > b.
+func NewTreeMapWithComparator[K comparable, V any](compare func(a, b K) int) *TreeMap[K, V] {
+	return &TreeMap[K, V]{tree: newRedBlackTree[K, V](compare)}
+}
+
+// NewTreeMapFromMap returns a TreeMap ordered by the natural order of the keys with the key-value pairs of m
+func NewTreeMapFromMap[K constraints.Ordered, V any](m Map[K, V]) *TreeMap[K, V] {
+	tm := NewTreeMap[K, V]()
+	tm.PutAll(m)
+	return tm
+}
+
+// compareOrdered compares the keys like cmp.Compare, a NaN is less than any other key and equal to another NaN,
+// so that the NaN keys are ordered consistently.
+func compareOrdered[K constraints.Ordered](a, b K) int {
+	// only a NaN is not equal to itself
+	aNaN, bNaN := a != a, b != b
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareByKind compares the keys by their kinds, it is used by the zero value of TreeMap
+func compareByKind[K any](a, b K) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(va.Int(), vb.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareOrdered(va.Uint(), vb.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(va.Float(), vb.Float())
+	case reflect.String:
+		return compareOrdered(va.String(), vb.String())
+	default:
+		panic(fmt.Sprintf("collection: %T has no natural order, use NewTreeMapWithComparator", a))
+	}
+}
+
+// rbTree returns the tree, which is created for the zero value
+func (m *TreeMap[K, V]) rbTree() *redBlackTree[K, V] {
+	if m.tree == nil {
+		m.tree = newRedBlackTree[K, V](compareByKind[K])
+	}
+	return m.tree
+}
+
+func (m *TreeMap[K, V]) tooLow(key K) bool {
+	if m.from == nil {
+		return false
+	}
+	c := m.rbTree().compare(key, m.from.key)
+	return c < 0 || (c == 0 && !m.from.inclusive)
+}
+
+func (m *TreeMap[K, V]) tooHigh(key K) bool {
+	if m.to == nil {
+		return false
+	}
+	c := m.rbTree().compare(key, m.to.key)
+	return c > 0 || (c == 0 && !m.to.inclusive)
+}
+
+func (m *TreeMap[K, V]) inRange(key K) bool {
+	return !m.tooLow(key) && !m.tooHigh(key)
+}
+
+func (m *TreeMap[K, V]) isView() bool {
+	return m.from != nil || m.to != nil
+}
+
+// lowest returns the node with the least key in the range
+func (m *TreeMap[K, V]) lowest() *treeNode[K, V] {
+	var n *treeNode[K, V]
+	if m.from == nil {
+		n = m.rbTree().first()
+	} else {
+		n = m.rbTree().ceiling(m.from.key, m.from.inclusive)
+	}
+	if n == nil || m.tooHigh(n.key) {
+		return nil
+	}
+	return n
+}
+
+// highest returns the node with the greatest key in the range
+func (m *TreeMap[K, V]) highest() *treeNode[K, V] {
+	var n *treeNode[K, V]
+	if m.to == nil {
+		n = m.rbTree().last()
+	} else {
+		n = m.rbTree().floor(m.to.key, m.to.inclusive)
+	}
+	if n == nil || m.tooLow(n.key) {
+		return nil
+	}
+	return n
+}
+
+// floor returns the node with the greatest key in the range less than (or equal to) the key
+func (m *TreeMap[K, V]) floor(key K, inclusive bool) *treeNode[K, V] {
+	n := m.rbTree().floor(key, inclusive)
+	if n != nil && m.tooHigh(n.key) {
+		return m.highest()
+	}
+	if n == nil || m.tooLow(n.key) {
+		return nil
+	}
+	return n
+}
+
+// ceiling returns the node with the least key in the range greater than (or equal to) the key
+func (m *TreeMap[K, V]) ceiling(key K, inclusive bool) *treeNode[K, V] {
+	n := m.rbTree().ceiling(key, inclusive)
+	if n != nil && m.tooLow(n.key) {
+		return m.lowest()
+	}
+	if n == nil || m.tooHigh(n.key) {
+		return nil
+	}
+	return n
+}
+
+// node returns the node of the key in the range
+func (m *TreeMap[K, V]) node(key K) *treeNode[K, V] {
+	if !m.inRange(key) {
+		return nil
+	}
+	return m.rbTree().get(key)
+}
+
+func (m *TreeMap[K, V]) Put(key K, value V) (oldValue V, oldValueFound bool) {
+	if !m.inRange(key) {
+		panic(fmt.Sprintf("collection: key %v out of range", key))
+	}
+	return m.rbTree().put(key, value)
+}
+
+func (m *TreeMap[K, V]) PutIfAbsent(key K, newValue V) {
+	if m.node(key) == nil {
+		m.Put(key, newValue)
+	}
+}
+
+func (m *TreeMap[K, V]) PutAll(other Map[K, V]) {
+	other.ForEach(func(key K, value V) {
+		m.Put(key, value)
+	})
+}
+
+func (m *TreeMap[K, V]) ComputeIfAbsent(key K, mapping func(key K) V) {
+	if m.node(key) == nil {
+		m.Put(key, mapping(key))
+	}
+}
+
+func (m *TreeMap[K, V]) ComputeIfPresent(key K,
+	remapping func(key K, oldValue V) (newValue V, action RemappingAction),
+) {
+	if n := m.node(key); n != nil {
+		newValue, action := remapping(key, n.value)
+		switch action {
+		case Replace:
+			n.value = newValue
+		case Remove:
+			m.rbTree().delete(n)
+		}
+	}
+}
+
+func (m *TreeMap[K, V]) Get(key K) (value V, found bool) {
+	if n := m.node(key); n != nil {
+		return n.value, true
+	}
+	return value, false
+}
+
+func (m *TreeMap[K, V]) GetOrDefault(key K, defaultValue V) V {
+	if n := m.node(key); n != nil {
+		return n.value
+	}
+	return defaultValue
+}
+
+func (m *TreeMap[K, V]) ContainsKey(key K) bool {
+	return m.node(key) != nil
+}
+
+func (m *TreeMap[K, V]) Keys() []K {
+	keys := make([]K, 0)
+	m.ForEach(func(key K, value V) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+func (m *TreeMap[K, V]) Values() []V {
+	values := make([]V, 0)
+	m.ForEach(func(key K, value V) {
+		values = append(values, value)
+	})
+	return values
+}
+
+func (m *TreeMap[K, V]) ForEach(consumer func(key K, value V)) {
+	for n := m.lowest(); n != nil && !m.tooHigh(n.key); n = successor(n) {
+		consumer(n.key, n.value)
+	}
+}
+
+func (m *TreeMap[K, V]) ForEachIndexed(consumer func(index int, key K, value V) (stop bool)) {
+	index := 0
+	for n := m.lowest(); n != nil && !m.tooHigh(n.key); n = successor(n) {
+		if consumer(index, n.key, n.value) {
+			break
+		}
+		index++
+	}
+}
+
+func (m *TreeMap[K, V]) Iterator() collection.Iterator[Pair[K, V]] {
+	return &treeMapIterator[K, V]{m: m, next: m.lowest()}
+}
+
+func (m *TreeMap[K, V]) Remove(key K) (value V, found bool) {
+	if n := m.node(key); n != nil {
+		value = n.value
+		m.rbTree().delete(n)
+		return value, true
+	}
+	return value, false
+}
+
+func (m *TreeMap[K, V]) RemoveIf(predicate func(key K, value V) bool) {
+	it := m.Iterator()
+	for pair, ok := it.Next(); ok; pair, ok = it.Next() {
+		if predicate(pair.Key, pair.Value) {
+			it.Remove()
+		}
+	}
+}
+
+func (m *TreeMap[K, V]) Clear() {
+	if !m.isView() {
+		m.rbTree().clear()
+		return
+	}
+	m.RemoveIf(func(key K, value V) bool {
+		return true
+	})
+}
+
+func (m *TreeMap[K, V]) IsEmpty() bool {
+	return m.lowest() == nil
+}
+
+// Size returns the number of key-value pairs, it takes O(n) time for a view
+func (m *TreeMap[K, V]) Size() int {
+	if !m.isView() {
+		return m.rbTree().size
+	}
+	size := 0
+	m.ForEach(func(key K, value V) {
+		size++
+	})
+	return size
+}
+
+func (m *TreeMap[K, V]) AsBuiltinMap() map[K]V {
+	builtinMap := make(map[K]V)
+	m.ForEach(func(key K, value V) {
+		builtinMap[key] = value
+	})
+	return builtinMap
+}
+
+func (m *TreeMap[K, V]) FirstKey() (key K, found bool) {
+	if n := m.lowest(); n != nil {
+		return n.key, true
+	}
+	return key, false
+}
+
+func (m *TreeMap[K, V]) LastKey() (key K, found bool) {
+	if n := m.highest(); n != nil {
+		return n.key, true
+	}
+	return key, false
+}
+
+func (m *TreeMap[K, V]) Floor(key K) (pair Pair[K, V], found bool) {
+	return nodePair(m.floor(key, true))
+}
+
+func (m *TreeMap[K, V]) Ceiling(key K) (pair Pair[K, V], found bool) {
+	return nodePair(m.ceiling(key, true))
+}
+
+func (m *TreeMap[K, V]) Lower(key K) (pair Pair[K, V], found bool) {
+	return nodePair(m.floor(key, false))
+}
+
+func (m *TreeMap[K, V]) Higher(key K) (pair Pair[K, V], found bool) {
+	return nodePair(m.ceiling(key, false))
+}
+
+func nodePair[K any, V any](n *treeNode[K, V]) (pair Pair[K, V], found bool) {
+	if n == nil {
+		return pair, false
+	}
+	return Pair[K, V]{Key: n.key, Value: n.value}, true
+}
+
+// HeadMap returns a view of the keys less than (or equal to) toKey,
+// if m is a view, the range of the returned view is also limited by the range of m.
+func (m *TreeMap[K, V]) HeadMap(toKey K, inclusive bool) NavigableMap[K, V] {
+	return m.view(nil, &treeBound[K]{key: toKey, inclusive: inclusive})
+}
+
+// TailMap returns a view of the keys greater than (or equal to) fromKey,
+// if m is a view, the range of the returned view is also limited by the range of m.
+func (m *TreeMap[K, V]) TailMap(fromKey K, inclusive bool) NavigableMap[K, V] {
+	return m.view(&treeBound[K]{key: fromKey, inclusive: inclusive}, nil)
+}
+
+// SubMap returns a view of the keys from fromKey to toKey,
+// if m is a view, the range of the returned view is also limited by the range of m.
+func (m *TreeMap[K, V]) SubMap(fromKey K, fromInclusive bool, toKey K, toInclusive bool) NavigableMap[K, V] {
+	if m.rbTree().compare(fromKey, toKey) > 0 {
+		panic(fmt.Sprintf("collection: fromKey %v is greater than toKey %v", fromKey, toKey))
+	}
+	return m.view(&treeBound[K]{key: fromKey, inclusive: fromInclusive}, &treeBound[K]{key: toKey, inclusive: toInclusive})
+}
+
+// view returns a view whose range is the intersection of the range of m and the bounds
+func (m *TreeMap[K, V]) view(from, to *treeBound[K]) *TreeMap[K, V] {
+	tree := m.rbTree()
+	v := &TreeMap[K, V]{tree: tree, from: m.from, to: m.to}
+	if from != nil && (v.from == nil || tree.compare(from.key, v.from.key) > 0 ||
+		tree.compare(from.key, v.from.key) == 0 && !from.inclusive) {
+		v.from = from
+	}
+	if to != nil && (v.to == nil || tree.compare(to.key, v.to.key) < 0 ||
+		tree.compare(to.key, v.to.key) == 0 && !to.inclusive) {
+		v.to = to
+	}
+	return v
+}
+
+func (m *TreeMap[K, V]) Comparator() func(a, b K) int {
+	return m.rbTree().compare
+}
+
+func (m *TreeMap[K, V]) DescendingKeys() []K {
+	keys := make([]K, 0)
+	for n := m.highest(); n != nil && !m.tooLow(n.key); n = predecessor(n) {
+		keys = append(keys, n.key)
+	}
+	return keys
+}
+
+func (m *TreeMap[K, V]) DescendingIterator() collection.Iterator[Pair[K, V]] {
+	return &treeMapIterator[K, V]{m: m, next: m.highest(), descending: true}
+}
+
+func (m *TreeMap[K, V]) String() string {
+	return MapString[K, V](m)
+}
+
+func (m *TreeMap[K, V]) MarshalJSON() ([]byte, error) {
+	return MarshalJSON[K, V](m)
+}
+
+func (m *TreeMap[K, V]) UnmarshalJSON(bytes []byte) error {
+	m.Clear()
+	return UnmarshalJSON[K, V](m, bytes)
+}
+
+// treeMapIterator iterates over the tree in ascending or descending order of keys within the range of the map
+type treeMapIterator[K comparable, V any] struct {
+	m          *TreeMap[K, V]
+	next       *treeNode[K, V]
+	last       *treeNode[K, V]
+	descending bool
+}
+
+func (it *treeMapIterator[K, V]) Next() (pair Pair[K, V], ok bool) {
+	if it.next == nil || !it.m.inRange(it.next.key) {
+		return pair, false
+	}
+	it.last = it.next
+	if it.descending {
+		it.next = predecessor(it.next)
+	} else {
+		it.next = successor(it.next)
+	}
+	return Pair[K, V]{Key: it.last.key, Value: it.last.value}, true
+}
+
+func (it *treeMapIterator[K, V]) Remove() {
+	if it.last == nil {
+		panic("collection: Remove must be called after Next")
+	}
+	// deleting a node with two children moves its successor into it,
+	// so the successor to be returned next is the last node
+	if !it.descending && it.last.left != nil && it.last.right != nil {
+		it.next = it.last
+	}
+	it.m.rbTree().delete(it.last)
+	it.last = nil
+}
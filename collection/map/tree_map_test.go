@@ -0,0 +1,253 @@
+package _map
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// checkRedBlackTree checks the invariants of the red-black tree and returns its black height
+func checkRedBlackTree[K any, V any](t *testing.T, tree *redBlackTree[K, V], n *treeNode[K, V]) int {
+	if n == nil {
+		return 1
+	}
+	if n.color == red {
+		require.Equal(t, black, colorOf(n.left), "a red node must have black children")
+		require.Equal(t, black, colorOf(n.right), "a red node must have black children")
+	}
+	if n.left != nil {
+		require.Same(t, n, n.left.parent)
+		require.Negative(t, tree.compare(n.left.key, n.key))
+	}
+	if n.right != nil {
+		require.Same(t, n, n.right.parent)
+		require.Positive(t, tree.compare(n.right.key, n.key))
+	}
+	height := checkRedBlackTree(t, tree, n.left)
+	require.Equal(t, height, checkRedBlackTree(t, tree, n.right), "the paths must have the same black height")
+	if n.color == black {
+		height++
+	}
+	return height
+}
+
+func TestTreeMap_Random(t *testing.T) {
+	m := NewTreeMap[int, int]()
+	expected := make(map[int]int)
+	for i := 0; i < 5000; i++ {
+		key := rand.Intn(500)
+		if rand.Intn(3) == 0 {
+			value, found := m.Remove(key)
+			expectedValue, expectedFound := expected[key]
+			require.Equal(t, expectedFound, found)
+			require.Equal(t, expectedValue, value)
+			delete(expected, key)
+		} else {
+			m.Put(key, i)
+			expected[key] = i
+		}
+		if i%100 == 0 {
+			require.Equal(t, black, colorOf(m.tree.root))
+			checkRedBlackTree(t, m.tree, m.tree.root)
+		}
+	}
+
+	keys := Keys(expected)
+	sort.Ints(keys)
+	require.Equal(t, len(expected), m.Size())
+	require.Equal(t, keys, m.Keys())
+	require.Equal(t, expected, m.AsBuiltinMap())
+}
+
+func TestTreeMap_Navigation(t *testing.T) {
+	m := NewTreeMap[int, string]()
+	require.True(t, m.IsEmpty())
+	_, found := m.FirstKey()
+	require.False(t, found)
+
+	for _, key := range []int{50, 10, 40, 20, 30} {
+		m.Put(key, strings.Repeat("x", key/10))
+	}
+	first, _ := m.FirstKey()
+	last, _ := m.LastKey()
+	require.Equal(t, 10, first)
+	require.Equal(t, 50, last)
+
+	tests := []struct {
+		name  string
+		fn    func(int) (Pair[int, string], bool)
+		key   int
+		found bool
+		want  int
+	}{
+		{"floor equal", m.Floor, 30, true, 30},
+		{"floor between", m.Floor, 35, true, 30},
+		{"floor below", m.Floor, 5, false, 0},
+		{"ceiling equal", m.Ceiling, 30, true, 30},
+		{"ceiling between", m.Ceiling, 35, true, 40},
+		{"ceiling above", m.Ceiling, 55, false, 0},
+		{"lower equal", m.Lower, 30, true, 20},
+		{"lower first", m.Lower, 10, false, 0},
+		{"higher equal", m.Higher, 30, true, 40},
+		{"higher last", m.Higher, 50, false, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pair, found := test.fn(test.key)
+			require.Equal(t, test.found, found)
+			require.Equal(t, test.want, pair.Key)
+		})
+	}
+
+	pair, _ := m.Ceiling(25)
+	require.Equal(t, Pair[int, string]{Key: 30, Value: "xxx"}, pair)
+	require.Equal(t, []int{50, 40, 30, 20, 10}, m.DescendingKeys())
+}
+
+func TestTreeMap_Views(t *testing.T) {
+	m := NewTreeMap[int, int]()
+	for i := 1; i <= 10; i++ {
+		m.Put(i, i*i)
+	}
+
+	head := m.HeadMap(4, false)
+	require.Equal(t, []int{1, 2, 3}, head.Keys())
+	require.Equal(t, 3, head.Size())
+	require.False(t, head.ContainsKey(4))
+	_, found := head.Get(5)
+	require.False(t, found)
+
+	tail := m.TailMap(8, true)
+	require.Equal(t, []int{8, 9, 10}, tail.Keys())
+	require.Equal(t, []int{10, 9, 8}, tail.DescendingKeys())
+
+	sub := m.SubMap(3, false, 7, true)
+	require.Equal(t, []int{4, 5, 6, 7}, sub.Keys())
+	floor, _ := sub.Floor(100)
+	require.Equal(t, 7, floor.Key)
+	ceiling, _ := sub.Ceiling(0)
+	require.Equal(t, 4, ceiling.Key)
+	_, found = sub.Lower(4)
+	require.False(t, found)
+
+	// a view of a view is limited by both ranges
+	require.Equal(t, []int{4, 5}, sub.HeadMap(5, true).Keys())
+	require.Equal(t, []int{4, 5, 6, 7}, sub.HeadMap(100, true).Keys())
+
+	// the changes of a view are visible in the map and vice versa
+	sub.Remove(5)
+	require.False(t, m.ContainsKey(5))
+	m.Remove(6)
+	require.Equal(t, []int{4, 7}, sub.Keys())
+	require.Panics(t, func() {
+		sub.Put(8, 64)
+	})
+	sub.Clear()
+	require.True(t, sub.IsEmpty())
+	require.Equal(t, []int{1, 2, 3, 8, 9, 10}, m.Keys())
+}
+
+func TestTreeMap_Iterator(t *testing.T) {
+	m := NewTreeMap[int, int]()
+	for i := 0; i < 100; i++ {
+		m.Put(i, i)
+	}
+
+	it := m.Iterator()
+	var keys []int
+	for pair, ok := it.Next(); ok; pair, ok = it.Next() {
+		keys = append(keys, pair.Key)
+		if pair.Key%2 == 0 {
+			it.Remove()
+		}
+	}
+	require.Len(t, keys, 100)
+	require.Equal(t, 50, m.Size())
+	checkRedBlackTree(t, m.tree, m.tree.root)
+
+	it = m.DescendingIterator()
+	keys = keys[:0]
+	for pair, ok := it.Next(); ok; pair, ok = it.Next() {
+		keys = append(keys, pair.Key)
+		if pair.Key%3 == 0 {
+			it.Remove()
+		}
+	}
+	require.Len(t, keys, 50)
+	require.Equal(t, 99, keys[0])
+	require.Equal(t, 1, keys[49])
+	require.Equal(t, 33, m.Size())
+
+	m.RemoveIf(func(key int, value int) bool {
+		return key > 10
+	})
+	require.Equal(t, []int{1, 5, 7}, m.Keys())
+	require.Panics(t, func() {
+		m.Iterator().Remove()
+	})
+}
+
+func TestTreeMap_Comparator(t *testing.T) {
+	type version struct {
+		major, minor int
+	}
+	m := NewTreeMapWithComparator[version, string](func(a, b version) int {
+		if a.major != b.major {
+			return a.major - b.major
+		}
+		return a.minor - b.minor
+	})
+	m.Put(version{1, 10}, "c")
+	m.Put(version{1, 2}, "b")
+	m.Put(version{0, 9}, "a")
+	require.Equal(t, []string{"a", "b", "c"}, m.Values())
+}
+
+func TestTreeMap_FloatKeys(t *testing.T) {
+	for _, m := range []*TreeMap[float64, string]{NewTreeMap[float64, string](), {}} {
+		m.Put(2, "two")
+		m.Put(math.NaN(), "nan")
+		m.Put(-1, "minus one")
+		m.Put(math.Inf(-1), "-inf")
+		// a NaN is equal to another NaN, and does not replace other keys
+		m.Put(math.NaN(), "NaN")
+		require.Equal(t, 4, m.Size())
+		require.Equal(t, []string{"NaN", "-inf", "minus one", "two"}, m.Values())
+		keys := m.Keys()
+		require.True(t, math.IsNaN(keys[0]))
+		require.Equal(t, []float64{math.Inf(-1), -1, 2}, keys[1:])
+
+		value, found := m.Get(math.NaN())
+		require.True(t, found)
+		require.Equal(t, "NaN", value)
+		value, found = m.Get(2)
+		require.True(t, found)
+		require.Equal(t, "two", value)
+		checkRedBlackTree(t, m.tree, m.tree.root)
+
+		_, found = m.Remove(math.NaN())
+		require.True(t, found)
+		require.Equal(t, []float64{math.Inf(-1), -1, 2}, m.Keys())
+	}
+}
+
+func TestTreeMap_JSON(t *testing.T) {
+	m := NewTreeMap[string, int]()
+	m.Put("c", 3)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	require.Equal(t, "{a: 1, b: 2, c: 3}", m.String())
+
+	bz, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1,"b":2,"c":3}`, string(bz))
+
+	// the zero value is ordered by the natural order of the keys
+	var m2 TreeMap[string, int]
+	require.NoError(t, json.Unmarshal([]byte(`{"z":26,"y":25}`), &m2))
+	require.Equal(t, []string{"y", "z"}, m2.Keys())
+}
@@ -0,0 +1,144 @@
+package set
+
+import (
+	"fmt"
+	_map "github.com/carter-ya/go-tools/collection/map"
+)
+
+// newEmpty returns an empty set of the same concrete type as s,
+// a TreeSet keeps the order of s, and an unknown Set is replaced by a HashSet.
+func newEmpty[E comparable](s Set[E], size int) Set[E] {
+	switch s := s.(type) {
+	case HashSet[E]:
+		return NewHashSetWithSize[E](size)
+	case *LinkedHashSet[E]:
+		lhs := NewLinkedHashSetWithSize[E](size)
+		return &lhs
+	case *TreeSet[E]:
+		return NewTreeSetWithComparator[E](s.navigableMap().Comparator())
+	default:
+		return NewHashSetWithSize[E](size)
+	}
+}
+
+// smallerFirst returns the smaller set first
+func smallerFirst[E comparable](a, b Set[E]) (smaller, larger Set[E]) {
+	if a.Size() <= b.Size() {
+		return a, b
+	}
+	return b, a
+}
+
+// Union returns a new set of the elements in a or b, whose concrete type is the same as a.
+func Union[E comparable](a, b Set[E]) Set[E] {
+	result := newEmpty(a, a.Size()+b.Size())
+	result.AddAll(a)
+	result.AddAll(b)
+	return result
+}
+
+// Intersection returns a new set of the elements in both a and b, whose concrete type is the same as a.
+//
+// The smaller set is iterated, so the elements of a LinkedHashSet are in the order of the smaller set.
+func Intersection[E comparable](a, b Set[E]) Set[E] {
+	smaller, larger := smallerFirst(a, b)
+	result := newEmpty(a, smaller.Size())
+	smaller.ForEach(func(e E) {
+		if larger.Contains(e) {
+			result.Add(e)
+		}
+	})
+	return result
+}
+
+// Difference returns a new set of the elements in a but not in b, whose concrete type is the same as a.
+func Difference[E comparable](a, b Set[E]) Set[E] {
+	result := newEmpty(a, a.Size())
+	a.ForEach(func(e E) {
+		if !b.Contains(e) {
+			result.Add(e)
+		}
+	})
+	return result
+}
+
+// SymmetricDifference returns a new set of the elements in either a or b but not in both,
+// whose concrete type is the same as a.
+func SymmetricDifference[E comparable](a, b Set[E]) Set[E] {
+	result := newEmpty(a, a.Size()+b.Size())
+	a.ForEach(func(e E) {
+		if !b.Contains(e) {
+			result.Add(e)
+		}
+	})
+	b.ForEach(func(e E) {
+		if !a.Contains(e) {
+			result.Add(e)
+		}
+	})
+	return result
+}
+
+// IsSubsetOf returns true if all elements of a are in b
+func IsSubsetOf[E comparable](a, b Set[E]) bool {
+	return a.Size() <= b.Size() && b.ContainsAll(a)
+}
+
+// IsSupersetOf returns true if all elements of b are in a
+func IsSupersetOf[E comparable](a, b Set[E]) bool {
+	return IsSubsetOf(b, a)
+}
+
+// IsDisjoint returns true if a and b have no element in common, the smaller set is iterated.
+func IsDisjoint[E comparable](a, b Set[E]) bool {
+	smaller, larger := smallerFirst(a, b)
+	disjoint := true
+	smaller.ForEachIndexed(func(_ int, e E) (stop bool) {
+		disjoint = !larger.Contains(e)
+		return !disjoint
+	})
+	return disjoint
+}
+
+// Equal returns true if a and b have the same elements, regardless of their concrete types and orders.
+func Equal[E comparable](a, b Set[E]) bool {
+	return a.Size() == b.Size() && b.ContainsAll(a)
+}
+
+// maxPowerSetSize is the maximum size of a set whose power set can be computed
+const maxPowerSetSize = 20
+
+// PowerSet returns all subsets of s, whose concrete types are the same as s.
+// The subsets are ordered by the bitmasks of the elements in the iteration order of s,
+// e.g. the power set of [a, b] is [[], [a], [b], [a, b]].
+//
+// It panics if s has more than 20 elements, whose power set has more than a million subsets.
+func PowerSet[E comparable](s Set[E]) []Set[E] {
+	if s.Size() > maxPowerSetSize {
+		panic(fmt.Sprintf("collection: the power set of %d elements is too large", s.Size()))
+	}
+	elements := s.AsSlice()
+	subsets := make([]Set[E], 0, 1<<len(elements))
+	for mask := 0; mask < 1<<len(elements); mask++ {
+		subset := newEmpty(s, len(elements))
+		for i, e := range elements {
+			if mask&(1<<i) != 0 {
+				subset.Add(e)
+			}
+		}
+		subsets = append(subsets, subset)
+	}
+	return subsets
+}
+
+// CartesianProduct returns all pairs of an element of a and an element of b,
+// in the iteration order of a and then b.
+func CartesianProduct[A comparable, B comparable](a Set[A], b Set[B]) []_map.Pair[A, B] {
+	pairs := make([]_map.Pair[A, B], 0, a.Size()*b.Size())
+	a.ForEach(func(x A) {
+		b.ForEach(func(y B) {
+			pairs = append(pairs, _map.Pair[A, B]{Key: x, Value: y})
+		})
+	})
+	return pairs
+}
@@ -0,0 +1,110 @@
+package set
+
+import (
+	_map "github.com/carter-ya/go-tools/collection/map"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAlgebra_HashSet(t *testing.T) {
+	a := NewHashSetFromSlice([]int{1, 2, 3, 4})
+	b := NewHashSetFromSlice([]int{3, 4, 5})
+
+	union := Union[int](a, b)
+	require.IsType(t, HashSet[int]{}, union)
+	require.ElementsMatch(t, []int{1, 2, 3, 4, 5}, union.AsSlice())
+	require.ElementsMatch(t, []int{3, 4}, Intersection[int](a, b).AsSlice())
+	require.ElementsMatch(t, []int{1, 2}, Difference[int](a, b).AsSlice())
+	require.ElementsMatch(t, []int{5}, Difference[int](b, a).AsSlice())
+	require.ElementsMatch(t, []int{1, 2, 5}, SymmetricDifference[int](a, b).AsSlice())
+
+	// the operands are not modified
+	require.Equal(t, 4, a.Size())
+	require.Equal(t, 3, b.Size())
+
+	require.False(t, IsSubsetOf[int](b, a))
+	require.True(t, IsSubsetOf[int](Intersection[int](a, b), a))
+	require.True(t, IsSupersetOf[int](a, Difference[int](a, b)))
+	require.False(t, IsDisjoint[int](a, b))
+	require.True(t, IsDisjoint[int](Difference[int](a, b), b))
+	require.True(t, IsDisjoint[int](NewHashSet[int](), a))
+}
+
+func TestAlgebra_LinkedHashSet(t *testing.T) {
+	a := NewLinkedHashSetFromSlice([]string{"d", "c", "b", "a"})
+	b := NewLinkedHashSetFromSlice([]string{"e", "c", "a"})
+
+	union := Union[string](&a, &b)
+	require.IsType(t, &LinkedHashSet[string]{}, union)
+	require.Equal(t, []string{"d", "c", "b", "a", "e"}, union.AsSlice())
+	// the smaller set is iterated
+	require.Equal(t, []string{"c", "a"}, Intersection[string](&a, &b).AsSlice())
+	require.Equal(t, []string{"d", "b"}, Difference[string](&a, &b).AsSlice())
+	require.Equal(t, []string{"d", "b", "e"}, SymmetricDifference[string](&a, &b).AsSlice())
+
+	// the result has the type of the first operand
+	hs := NewHashSetFromSlice([]string{"a", "z"})
+	require.IsType(t, HashSet[string]{}, Union[string](hs, &a))
+	require.IsType(t, &LinkedHashSet[string]{}, Union[string](&a, hs))
+}
+
+func TestAlgebra_TreeSet(t *testing.T) {
+	descending := func(a, b int) int {
+		return b - a
+	}
+	a := NewTreeSetWithComparator[int](descending)
+	a.AddAll(NewHashSetFromSlice([]int{1, 2, 3}))
+	b := NewTreeSetFromSlice([]int{2, 3, 4})
+
+	union := Union[int](a, b)
+	require.IsType(t, &TreeSet[int]{}, union)
+	require.Equal(t, []int{4, 3, 2, 1}, union.AsSlice())
+	require.Equal(t, []int{1, 2, 3, 4}, Union[int](b, a).AsSlice())
+}
+
+func TestAlgebra_Equal(t *testing.T) {
+	hs := NewHashSetFromSlice([]int{3, 1, 2})
+	lhs := NewLinkedHashSetFromSlice([]int{1, 2, 3})
+	ts := NewTreeSetFromSlice([]int{2, 3, 1})
+	require.True(t, Equal[int](hs, &lhs))
+	require.True(t, Equal[int](&lhs, ts))
+	require.True(t, Equal[int](NewHashSet[int](), NewTreeSet[int]()))
+
+	lhs.Add(4)
+	require.False(t, Equal[int](hs, &lhs))
+	require.True(t, IsSubsetOf[int](ts, &lhs))
+	require.True(t, IsSupersetOf[int](&lhs, hs))
+}
+
+func TestAlgebra_PowerSet(t *testing.T) {
+	s := NewLinkedHashSetFromSlice([]string{"a", "b", "c"})
+	var subsets [][]string
+	for _, subset := range PowerSet[string](&s) {
+		require.IsType(t, &LinkedHashSet[string]{}, subset)
+		subsets = append(subsets, subset.AsSlice())
+	}
+	require.Equal(t, [][]string{
+		{}, {"a"}, {"b"}, {"a", "b"}, {"c"}, {"a", "c"}, {"b", "c"}, {"a", "b", "c"},
+	}, subsets)
+
+	require.Len(t, PowerSet[int](NewHashSet[int]()), 1)
+	require.Panics(t, func() {
+		s := NewHashSet[int]()
+		for i := 0; i <= maxPowerSetSize; i++ {
+			s.Add(i)
+		}
+		PowerSet[int](s)
+	})
+}
+
+func TestAlgebra_CartesianProduct(t *testing.T) {
+	a := NewTreeSetFromSlice([]int{2, 1})
+	b := NewLinkedHashSetFromSlice([]string{"x", "y"})
+	require.Equal(t, []_map.Pair[int, string]{
+		{Key: 1, Value: "x"},
+		{Key: 1, Value: "y"},
+		{Key: 2, Value: "x"},
+		{Key: 2, Value: "y"},
+	}, CartesianProduct[int, string](a, &b))
+	require.Empty(t, CartesianProduct[int, string](a, NewHashSet[string]()))
+}
@@ -133,6 +133,10 @@ func (lhs *LinkedHashSet[E]) Stream() stream.Stream {
 	})
 }
 
+func (lhs *LinkedHashSet[E]) Iterator() collection.Iterator[E] {
+	return &linkedHashSetIterator[E]{it: lhs.linkedMap.Iterator()}
+}
+
 func (lhs *LinkedHashSet[E]) String() string {
 	return collection.String[E](lhs)
 }
@@ -144,3 +148,16 @@ func (lhs *LinkedHashSet[E]) MarshalJSON() ([]byte, error) {
 func (lhs *LinkedHashSet[E]) UnmarshalJSON(bytes []byte) error {
 	return collection.UnmarshalJSON[E](lhs, bytes)
 }
+
+type linkedHashSetIterator[E comparable] struct {
+	it collection.Iterator[_map.Pair[E, struct{}]]
+}
+
+func (it *linkedHashSetIterator[E]) Next() (e E, ok bool) {
+	pair, ok := it.it.Next()
+	return pair.Key, ok
+}
+
+func (it *linkedHashSetIterator[E]) Remove() {
+	it.it.Remove()
+}
@@ -3,6 +3,7 @@ package set
 import (
 	"github.com/carter-ya/go-tools/collection"
 	"github.com/carter-ya/go-tools/stream"
+	"reflect"
 )
 
 type Set[E comparable] interface {
@@ -38,6 +39,8 @@ type Set[E comparable] interface {
 	AsSlice() []E
 	// Stream returns a stream of the elements in the set.
 	Stream() stream.Stream
+	// Iterator returns an iterator over the elements in the set.
+	Iterator() collection.Iterator[E]
 }
 
 type HashSet[E comparable] map[E]struct{}
@@ -176,6 +179,14 @@ func (h HashSet[E]) Stream() stream.Stream {
 	})
 }
 
+// Iterator returns an iterator over the elements, which iterates lazily like a range loop,
+// so the elements removed during the iteration are not returned, and the elements added may or may not be returned.
+func (h HashSet[E]) Iterator() collection.Iterator[E] {
+	it := &hashSetIterator[E]{h: h, iter: reflect.ValueOf(h).MapRange()}
+	it.elementValue = reflect.ValueOf(&it.element).Elem()
+	return it
+}
+
 func (h HashSet[E]) String() string {
 	return collection.String[E](h)
 }
@@ -187,3 +198,31 @@ func (h HashSet[E]) MarshalJSON() ([]byte, error) {
 func (h HashSet[E]) UnmarshalJSON(bytes []byte) error {
 	return collection.UnmarshalJSON[E](h, bytes)
 }
+
+// hashSetIterator iterates over a HashSet by a reflect.MapIter,
+// the current element is copied into element without allocations.
+type hashSetIterator[E comparable] struct {
+	h            HashSet[E]
+	iter         *reflect.MapIter
+	element      E
+	elementValue reflect.Value
+	// last is true if Next has returned an element which is not removed
+	last bool
+}
+
+func (it *hashSetIterator[E]) Next() (e E, ok bool) {
+	if !it.iter.Next() {
+		return e, false
+	}
+	it.elementValue.SetIterKey(it.iter)
+	it.last = true
+	return it.element, true
+}
+
+func (it *hashSetIterator[E]) Remove() {
+	if !it.last {
+		panic("collection: Remove must be called after Next")
+	}
+	delete(it.h, it.element)
+	it.last = false
+}
@@ -0,0 +1,36 @@
+package set
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestHashSet_Iterator(t *testing.T) {
+	s := NewHashSetFromSlice([]int{1, 2, 3, 4})
+	it := s.Iterator()
+	var elements []int
+	for e, ok := it.Next(); ok; e, ok = it.Next() {
+		elements = append(elements, e)
+		if e%2 == 0 {
+			it.Remove()
+		}
+	}
+	require.ElementsMatch(t, []int{1, 2, 3, 4}, elements)
+	require.ElementsMatch(t, []int{1, 3}, s.AsSlice())
+	require.Panics(t, func() {
+		s.Iterator().Remove()
+	})
+
+	// the iteration is lazy, so the elements removed after the first Next are not returned
+	s = NewHashSetFromSlice([]int{1, 2, 3, 4})
+	it = s.Iterator()
+	first, ok := it.Next()
+	require.True(t, ok)
+	for _, e := range s.AsSlice() {
+		if e != first {
+			s.Remove(e)
+		}
+	}
+	_, ok = it.Next()
+	require.False(t, ok)
+}
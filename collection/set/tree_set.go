@@ -0,0 +1,267 @@
+package set
+
+import (
+	"github.com/carter-ya/go-tools/collection"
+	_map "github.com/carter-ya/go-tools/collection/map"
+	"github.com/carter-ya/go-tools/stream"
+	"golang.org/x/exp/constraints"
+)
+
+// NavigableSet is a Set sorted by elements, which can be navigated by element and viewed by element ranges
+type NavigableSet[E comparable] interface {
+	Set[E]
+
+	// First returns the least element
+	First() (e E, found bool)
+	// Last returns the greatest element
+	Last() (e E, found bool)
+	// Floor returns the greatest element less than or equal to the given element
+	Floor(e E) (floor E, found bool)
+	// Ceiling returns the least element greater than or equal to the given element
+	Ceiling(e E) (ceiling E, found bool)
+	// Lower returns the greatest element strictly less than the given element
+	Lower(e E) (lower E, found bool)
+	// Higher returns the least element strictly greater than the given element
+	Higher(e E) (higher E, found bool)
+	// PollFirst removes and returns the least element
+	PollFirst() (e E, found bool)
+	// PollLast removes and returns the greatest element
+	PollLast() (e E, found bool)
+
+	// HeadSet returns a view of the elements less than (or equal to, if inclusive) toElement.
+	HeadSet(toElement E, inclusive bool) NavigableSet[E]
+	// TailSet returns a view of the elements greater than (or equal to, if inclusive) fromElement.
+	TailSet(fromElement E, inclusive bool) NavigableSet[E]
+	// SubSet returns a view of the elements ranging from fromElement to toElement.
+	SubSet(fromElement E, fromInclusive bool, toElement E, toInclusive bool) NavigableSet[E]
+
+	// DescendingIterator returns an iterator over the elements in descending order
+	DescendingIterator() collection.Iterator[E]
+}
+
+var _ NavigableSet[int] = (*TreeSet[int])(nil)
+
+// TreeSet is a NavigableSet based on the TreeMap, so it is not thread-safe.
+//
+// The elements are iterated, streamed and encoded to JSON in ascending order.
+// The views returned by HeadSet, TailSet and SubSet share the elements with the set.
+//
+// The zero value is an empty set ordered by the natural order of the elements,
+// whose kind must be an integer, a float or a string.
+type TreeSet[E comparable] struct {
+	treeMap _map.NavigableMap[E, struct{}]
+}
+
+func NewTreeSet[E constraints.Ordered]() *TreeSet[E] {
+	return &TreeSet[E]{
+		treeMap: _map.NewTreeMap[E, struct{}](),
+	}
+}
+
+func NewTreeSetWithComparator[E comparable](compare func(a, b E) int) *TreeSet[E] {
+	return &TreeSet[E]{
+		treeMap: _map.NewTreeMapWithComparator[E, struct{}](compare),
+	}
+}
+
+func NewTreeSetFromSlice[E constraints.Ordered](slice []E) *TreeSet[E] {
+	ts := NewTreeSet[E]()
+	for _, e := range slice {
+		ts.Add(e)
+	}
+	return ts
+}
+
+func NewTreeSetFromCollection[E constraints.Ordered](c collection.Collection[E]) *TreeSet[E] {
+	ts := NewTreeSet[E]()
+	ts.AddAll(c)
+	return ts
+}
+
+func NewTreeSetFromStream[E constraints.Ordered](stream stream.Stream) *TreeSet[E] {
+	ts := NewTreeSet[E]()
+	stream.ForEach(func(item any) {
+		ts.Add(item.(E))
+	})
+	return ts
+}
+
+// navigableMap returns the map, which is created for the zero value
+func (ts *TreeSet[E]) navigableMap() _map.NavigableMap[E, struct{}] {
+	if ts.treeMap == nil {
+		ts.treeMap = &_map.TreeMap[E, struct{}]{}
+	}
+	return ts.treeMap
+}
+
+func (ts *TreeSet[E]) Add(e E) bool {
+	ts.navigableMap().Put(e, struct{}{})
+	return true
+}
+
+func (ts *TreeSet[E]) AddAll(other collection.Collection[E]) bool {
+	other.ForEach(func(e E) {
+		ts.navigableMap().Put(e, struct{}{})
+	})
+	return true
+}
+
+func (ts *TreeSet[E]) Remove(e E) (found bool) {
+	_, found = ts.navigableMap().Remove(e)
+	return
+}
+
+func (ts *TreeSet[E]) RemoveAll(other collection.Collection[E]) bool {
+	other.ForEach(func(e E) {
+		ts.navigableMap().Remove(e)
+	})
+	return true
+}
+
+func (ts *TreeSet[E]) RemoveIf(predicate func(e E) bool) {
+	ts.navigableMap().RemoveIf(func(e E, _ struct{}) bool {
+		return predicate(e)
+	})
+}
+
+func (ts *TreeSet[E]) RetainAll(other collection.Collection[E]) {
+	ts.navigableMap().RemoveIf(func(e E, _ struct{}) bool {
+		return !other.Contains(e)
+	})
+}
+
+func (ts *TreeSet[E]) Clear() {
+	ts.navigableMap().Clear()
+}
+
+func (ts *TreeSet[E]) Contains(e E) bool {
+	return ts.navigableMap().ContainsKey(e)
+}
+
+func (ts *TreeSet[E]) ContainsAll(other collection.Collection[E]) bool {
+	yes := true
+	other.ForEachIndexed(func(_ int, e E) (stop bool) {
+		yes = ts.navigableMap().ContainsKey(e)
+		return !yes
+	})
+	return yes
+}
+
+func (ts *TreeSet[E]) IsEmpty() bool {
+	return ts.navigableMap().IsEmpty()
+}
+
+func (ts *TreeSet[E]) Size() int {
+	return ts.navigableMap().Size()
+}
+
+func (ts *TreeSet[E]) ForEach(consumer func(e E)) {
+	ts.navigableMap().ForEach(func(key E, _ struct{}) {
+		consumer(key)
+	})
+}
+
+func (ts *TreeSet[E]) ForEachIndexed(consumer func(index int, e E) (stop bool)) {
+	ts.navigableMap().ForEachIndexed(func(index int, key E, _ struct{}) (stop bool) {
+		return consumer(index, key)
+	})
+}
+
+func (ts *TreeSet[E]) AsSlice() []E {
+	return ts.navigableMap().Keys()
+}
+
+func (ts *TreeSet[E]) Stream() stream.Stream {
+	return stream.From(func(source chan<- any) {
+		ts.navigableMap().ForEach(func(key E, _ struct{}) {
+			source <- key
+		})
+	})
+}
+
+func (ts *TreeSet[E]) Iterator() collection.Iterator[E] {
+	return &treeSetIterator[E]{it: ts.navigableMap().Iterator()}
+}
+
+func (ts *TreeSet[E]) First() (e E, found bool) {
+	return ts.navigableMap().FirstKey()
+}
+
+func (ts *TreeSet[E]) Last() (e E, found bool) {
+	return ts.navigableMap().LastKey()
+}
+
+func (ts *TreeSet[E]) Floor(e E) (floor E, found bool) {
+	pair, found := ts.navigableMap().Floor(e)
+	return pair.Key, found
+}
+
+func (ts *TreeSet[E]) Ceiling(e E) (ceiling E, found bool) {
+	pair, found := ts.navigableMap().Ceiling(e)
+	return pair.Key, found
+}
+
+func (ts *TreeSet[E]) Lower(e E) (lower E, found bool) {
+	pair, found := ts.navigableMap().Lower(e)
+	return pair.Key, found
+}
+
+func (ts *TreeSet[E]) Higher(e E) (higher E, found bool) {
+	pair, found := ts.navigableMap().Higher(e)
+	return pair.Key, found
+}
+
+func (ts *TreeSet[E]) PollFirst() (e E, found bool) {
+	if e, found = ts.First(); found {
+		ts.Remove(e)
+	}
+	return
+}
+
+func (ts *TreeSet[E]) PollLast() (e E, found bool) {
+	if e, found = ts.Last(); found {
+		ts.Remove(e)
+	}
+	return
+}
+
+func (ts *TreeSet[E]) HeadSet(toElement E, inclusive bool) NavigableSet[E] {
+	return &TreeSet[E]{treeMap: ts.navigableMap().HeadMap(toElement, inclusive)}
+}
+
+func (ts *TreeSet[E]) TailSet(fromElement E, inclusive bool) NavigableSet[E] {
+	return &TreeSet[E]{treeMap: ts.navigableMap().TailMap(fromElement, inclusive)}
+}
+
+func (ts *TreeSet[E]) SubSet(fromElement E, fromInclusive bool, toElement E, toInclusive bool) NavigableSet[E] {
+	return &TreeSet[E]{treeMap: ts.navigableMap().SubMap(fromElement, fromInclusive, toElement, toInclusive)}
+}
+
+func (ts *TreeSet[E]) DescendingIterator() collection.Iterator[E] {
+	return &treeSetIterator[E]{it: ts.navigableMap().DescendingIterator()}
+}
+
+func (ts *TreeSet[E]) String() string {
+	return collection.String[E](ts)
+}
+
+func (ts *TreeSet[E]) MarshalJSON() ([]byte, error) {
+	return collection.MarshalJSON[E](ts)
+}
+
+func (ts *TreeSet[E]) UnmarshalJSON(bytes []byte) error {
+	return collection.UnmarshalJSON[E](ts, bytes)
+}
+
+type treeSetIterator[E comparable] struct {
+	it collection.Iterator[_map.Pair[E, struct{}]]
+}
+
+func (it *treeSetIterator[E]) Next() (e E, ok bool) {
+	pair, ok := it.it.Next()
+	return pair.Key, ok
+}
+
+func (it *treeSetIterator[E]) Remove() {
+	it.it.Remove()
+}
@@ -0,0 +1,76 @@
+package set
+
+import (
+	"encoding/json"
+	"github.com/carter-ya/go-tools/stream"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTreeSet_Navigation(t *testing.T) {
+	ts := NewTreeSetFromSlice([]int{5, 1, 9, 3, 7, 3})
+	require.Equal(t, 5, ts.Size())
+	require.Equal(t, []int{1, 3, 5, 7, 9}, ts.AsSlice())
+
+	first, _ := ts.First()
+	last, _ := ts.Last()
+	require.Equal(t, 1, first)
+	require.Equal(t, 9, last)
+
+	floor, _ := ts.Floor(4)
+	ceiling, _ := ts.Ceiling(4)
+	lower, _ := ts.Lower(5)
+	higher, _ := ts.Higher(5)
+	require.Equal(t, []int{3, 5, 3, 7}, []int{floor, ceiling, lower, higher})
+	_, found := ts.Higher(9)
+	require.False(t, found)
+
+	require.Equal(t, []int{1, 3}, ts.HeadSet(5, false).AsSlice())
+	require.Equal(t, []int{5, 7, 9}, ts.TailSet(5, true).AsSlice())
+	sub := ts.SubSet(1, false, 9, false)
+	require.Equal(t, []int{3, 5, 7}, sub.AsSlice())
+	sub.Remove(5)
+	require.False(t, ts.Contains(5))
+
+	polled, _ := ts.PollFirst()
+	require.Equal(t, 1, polled)
+	polled, _ = ts.PollLast()
+	require.Equal(t, 9, polled)
+	require.Equal(t, []int{3, 7}, ts.AsSlice())
+
+	var descending []int
+	it := ts.DescendingIterator()
+	for e, ok := it.Next(); ok; e, ok = it.Next() {
+		descending = append(descending, e)
+	}
+	require.Equal(t, []int{7, 3}, descending)
+
+	ts.Clear()
+	_, found = ts.PollFirst()
+	require.False(t, found)
+}
+
+func TestTreeSet_Stream(t *testing.T) {
+	ts := NewTreeSetFromStream[string](stream.Just([]string{"b", "c", "a"}))
+	require.Equal(t, []any{"a", "b", "c"}, ts.Stream().ToIfaceSlice())
+	require.Equal(t, "[a, b, c]", ts.String())
+}
+
+func TestTreeSet_JSON(t *testing.T) {
+	ts := NewTreeSetWithComparator[string](func(a, b string) int {
+		return len(a) - len(b)
+	})
+	ts.AddAll(NewHashSetFromSlice([]string{"ccc", "a", "bb"}))
+	bz, err := json.Marshal(ts)
+	require.NoError(t, err)
+	require.Equal(t, `["a","bb","ccc"]`, string(bz))
+
+	var snapshot struct {
+		Tags TreeSet[string]
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"Tags":["z","x","y"]}`), &snapshot))
+	require.Equal(t, []string{"x", "y", "z"}, snapshot.Tags.AsSlice())
+	bz, err = json.Marshal(&snapshot)
+	require.NoError(t, err)
+	require.Equal(t, `{"Tags":["x","y","z"]}`, string(bz))
+}
@@ -0,0 +1,140 @@
+package concurrent
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and creates timers, so that the time can be faked in tests, see FakeClock.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTimer returns a timer which sends the current time on its channel after the duration
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a Clock
+type Timer interface {
+	// C returns the channel on which the time is sent when the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it returns false if the timer has fired or been stopped
+	Stop() bool
+}
+
+// SystemClock is the Clock of the system time
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// FakeClock is a Clock whose time only moves by Advance or Set, the timers fire when their time is reached.
+//
+// Note: FakeClock is routine-safe.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock starting at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the time forward by d and fires the timers whose time is reached
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(c.now.Add(d))
+}
+
+// Set sets the time to now and fires the timers whose time is reached
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(now)
+}
+
+// Timers returns the number of timers which have not fired or been stopped
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *FakeClock) setLocked(now time.Time) {
+	c.now = now
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if now.Before(t.deadline) {
+			pending = append(pending, t)
+		} else {
+			t.ch <- now
+		}
+	}
+	c.timers = pending
+}
+
+// remove removes the timer, it returns false if the timer has fired or been stopped
+func (c *FakeClock) remove(timer *fakeTimer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, t := range c.timers {
+		if t == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	return t.clock.remove(t)
+}
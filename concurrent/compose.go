@@ -0,0 +1,194 @@
+package concurrent
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// completionNotifier is implemented by the futures of this package, which call back on completion
+type completionNotifier[T any] interface {
+	onComplete(callback func(t T, err error))
+}
+
+// whenComplete calls the callback with the result of f once it completes.
+//
+// The callback runs on the completing goroutine if f is a future of this package,
+// otherwise a goroutine waits for f.
+func whenComplete[T any](f Future[T], callback func(t T, err error)) {
+	if notifier, ok := f.(completionNotifier[T]); ok {
+		notifier.onComplete(callback)
+		return
+	}
+	go func() {
+		callback(f.Get())
+	}()
+}
+
+// Then returns a Future that applies fn to the result of f once f succeeds,
+// if f fails, the returned Future fails with the same error.
+//
+// fn runs on the goroutine completing f, so it should not block.
+func Then[T any, R any](f Future[T], fn func(t T) (R, error)) Future[R] {
+	next := newFuture[R]()
+	whenComplete(f, func(t T, err error) {
+		if err != nil {
+			var zero R
+			next.complete(zero, err)
+			return
+		}
+		next.complete(callSafely(func() (R, error) {
+			return fn(t)
+		}))
+	})
+	return next
+}
+
+// ThenCompose returns a Future that completes with the Future returned by fn once f succeeds,
+// if f fails, the returned Future fails with the same error.
+// The returned Future fails if fn panics or returns a nil Future.
+func ThenCompose[T any, R any](f Future[T], fn func(t T) Future[R]) Future[R] {
+	next := newFuture[R]()
+	whenComplete(f, func(t T, err error) {
+		if err != nil {
+			var zero R
+			next.complete(zero, err)
+			return
+		}
+		var composed Future[R]
+		_, err = callSafely(func() (struct{}, error) {
+			if composed = fn(t); composed == nil {
+				return struct{}{}, errors.New("concurrent: ThenCompose fn returned a nil Future")
+			}
+			return struct{}{}, nil
+		})
+		if err != nil {
+			var zero R
+			next.complete(zero, err)
+			return
+		}
+		whenComplete(composed, func(r R, err error) {
+			next.complete(r, err)
+		})
+	})
+	return next
+}
+
+// Combine returns a Future that applies fn to the results of f1 and f2 once both succeed,
+// if either fails, the returned Future fails with the first error.
+func Combine[T any, U any, R any](f1 Future[T], f2 Future[U], fn func(t T, u U) (R, error)) Future[R] {
+	next := newFuture[R]()
+	fail := func(err error) {
+		var zero R
+		next.complete(zero, err)
+	}
+
+	var (
+		t       T
+		u       U
+		pending = int32(2)
+	)
+	done := func() {
+		if atomic.AddInt32(&pending, -1) == 0 {
+			next.complete(callSafely(func() (R, error) {
+				return fn(t, u)
+			}))
+		}
+	}
+	whenComplete(f1, func(v T, err error) {
+		if err != nil {
+			fail(err)
+			return
+		}
+		t = v
+		done()
+	})
+	whenComplete(f2, func(v U, err error) {
+		if err != nil {
+			fail(err)
+			return
+		}
+		u = v
+		done()
+	})
+	return next
+}
+
+// Recover returns a Future that completes with the result of f if f succeeds,
+// otherwise with the result of applying fn to the error of f.
+func Recover[T any](f Future[T], fn func(err error) (T, error)) Future[T] {
+	next := newFuture[T]()
+	whenComplete(f, func(t T, err error) {
+		if err == nil {
+			next.complete(t, nil)
+			return
+		}
+		next.complete(callSafely(func() (T, error) {
+			return fn(err)
+		}))
+	})
+	return next
+}
+
+// AllOf returns a Future of the results of all futures in order once all of them succeed,
+// if any fails, the returned Future fails with the first error.
+func AllOf[T any](futures ...Future[T]) Future[[]T] {
+	next := newFuture[[]T]()
+	results := make([]T, len(futures))
+	if len(futures) == 0 {
+		next.complete(results, nil)
+		return next
+	}
+
+	pending := int32(len(futures))
+	for i, f := range futures {
+		func(i int, f Future[T]) {
+			whenComplete(f, func(t T, err error) {
+				if err != nil {
+					next.complete(nil, err)
+					return
+				}
+				results[i] = t
+				if atomic.AddInt32(&pending, -1) == 0 {
+					next.complete(results, nil)
+				}
+			})
+		}(i, f)
+	}
+	return next
+}
+
+// AnyOf returns a Future that completes with the result of the first completed future, successful or not.
+// It panics if there are no futures.
+func AnyOf[T any](futures ...Future[T]) Future[T] {
+	if len(futures) == 0 {
+		panic("concurrent: AnyOf requires at least one future")
+	}
+	next := newFuture[T]()
+	for _, f := range futures {
+		whenComplete(f, func(t T, err error) {
+			next.complete(t, err)
+		})
+	}
+	return next
+}
+
+// Promise is a Future completed manually by Complete or CompleteExceptionally
+type Promise[T any] struct {
+	*future[T]
+}
+
+// NewPromise returns an incomplete Promise
+func NewPromise[T any]() *Promise[T] {
+	return &Promise[T]{future: newFuture[T]()}
+}
+
+// Complete completes the promise with the value, it returns false if the promise has been completed
+func (p *Promise[T]) Complete(t T) bool {
+	return p.complete(t, nil)
+}
+
+// CompleteExceptionally completes the promise with the error, it returns false if the promise has been completed
+func (p *Promise[T]) CompleteExceptionally(err error) bool {
+	var zero T
+	return p.complete(zero, err)
+}
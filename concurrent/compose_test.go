@@ -0,0 +1,131 @@
+package concurrent
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// foreignFuture is a Future implemented outside of the package, which does not call back on completion
+type foreignFuture[T any] struct {
+	Future[T]
+}
+
+func TestThen(t *testing.T) {
+	p := NewPromise[int]()
+	f := Then[int, string](p, func(v int) (string, error) {
+		return strconv.Itoa(v * 2), nil
+	})
+	require.True(t, p.Complete(21))
+	require.False(t, p.Complete(1))
+	v, err := f.Get()
+	require.NoError(t, err)
+	require.Equal(t, "42", v)
+
+	failed := NewPromise[int]()
+	failed.CompleteExceptionally(errors.New("boom"))
+	_, err = Then[int, int](failed, func(v int) (int, error) {
+		t.Fatal("must not be called")
+		return 0, nil
+	}).Get()
+	require.EqualError(t, err, "boom")
+
+	_, err = Then[int, int](NewFuture(func() (int, error) { return 1, nil }), func(v int) (int, error) {
+		panic("panic in then")
+	}).Get()
+	require.ErrorContains(t, err, "panic in then")
+
+	foreign := NewPromise[int]()
+	foreign.Complete(1)
+	v2, err := Then[int, int](foreignFuture[int]{foreign}, func(v int) (int, error) {
+		return v + 1, nil
+	}).Get()
+	require.NoError(t, err)
+	require.Equal(t, 2, v2)
+}
+
+func TestThenCompose(t *testing.T) {
+	f := ThenCompose[int, int](NewFuture(func() (int, error) { return 2, nil }), func(v int) Future[int] {
+		return NewFuture(func() (int, error) { return v * v, nil })
+	})
+	v, err := f.Get()
+	require.NoError(t, err)
+	require.Equal(t, 4, v)
+
+	_, err = ThenCompose[int, int](NewFuture(func() (int, error) { return 2, nil }), func(v int) Future[int] {
+		return nil
+	}).Get()
+	require.EqualError(t, err, "concurrent: ThenCompose fn returned a nil Future")
+}
+
+func TestCombine(t *testing.T) {
+	f := Combine[int, string, string](
+		NewFuture(func() (int, error) { return 3, nil }),
+		NewFuture(func() (string, error) { return "x", nil }),
+		func(n int, s string) (string, error) {
+			return strconv.Itoa(n) + s, nil
+		},
+	)
+	v, err := f.Get()
+	require.NoError(t, err)
+	require.Equal(t, "3x", v)
+
+	_, err = Combine[int, int, int](
+		NewPromise[int](),
+		NewFuture(func() (int, error) { return 0, errors.New("boom") }),
+		func(a, b int) (int, error) { return a + b, nil },
+	).Get()
+	require.EqualError(t, err, "boom")
+}
+
+func TestRecover(t *testing.T) {
+	f := Recover[int](NewFuture(func() (int, error) { return 0, errors.New("boom") }), func(err error) (int, error) {
+		return -1, nil
+	})
+	v, err := f.Get()
+	require.NoError(t, err)
+	require.Equal(t, -1, v)
+
+	v, err = Recover[int](NewFuture(func() (int, error) { return 1, nil }), func(err error) (int, error) {
+		return -1, nil
+	}).Get()
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+}
+
+func TestAllOf(t *testing.T) {
+	futures := make([]Future[int], 0, 10)
+	for i := 0; i < 10; i++ {
+		futures = append(futures, func(i int) Future[int] {
+			return NewFuture(func() (int, error) {
+				time.Sleep(time.Duration(10-i) * time.Millisecond)
+				return i, nil
+			})
+		}(i))
+	}
+	v, err := AllOf(futures...).Get()
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, v)
+
+	v, err = AllOf[int]().Get()
+	require.NoError(t, err)
+	require.Empty(t, v)
+
+	_, err = AllOf[int](NewPromise[int](), NewFuture(func() (int, error) { return 0, errors.New("boom") })).Get()
+	require.EqualError(t, err, "boom")
+}
+
+func TestAnyOf(t *testing.T) {
+	v, err := AnyOf[int](
+		NewPromise[int](),
+		NewFuture(func() (int, error) { return 1, nil }),
+	).Get()
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+
+	require.Panics(t, func() {
+		AnyOf[int]()
+	})
+}
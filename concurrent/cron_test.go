@@ -0,0 +1,39 @@
+package concurrent
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	// 2024-01-01 is a Monday
+	from := time.Date(2024, time.January, 1, 10, 30, 15, 0, time.UTC)
+	tests := []struct {
+		expr string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.January, 1, 10, 31, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)},
+		{"*/20 9-17 * * mon-fri", time.Date(2024, time.January, 1, 10, 40, 0, 0, time.UTC)},
+		{"0 9 * * sat,sun", time.Date(2024, time.January, 6, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week
+		{"0 0 15 * fri", time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		{"30 10 1 1 *", time.Date(2025, time.January, 1, 10, 30, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, test := range tests {
+		schedule, err := ParseCron(test.expr)
+		require.NoError(t, err, test.expr)
+		require.Equal(t, test.next, schedule.Next(from), test.expr)
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *", "x * * * *"} {
+		_, err := ParseCron(expr)
+		require.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,376 @@
+package concurrent
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRejectedExecution is returned when an executor cannot accept a task,
+// because it is shut down or its workers and queue are full.
+var ErrRejectedExecution = errors.New("concurrent: task rejected")
+
+// DefaultKeepAliveTime is the default time an idle worker beyond the core pool size waits for a task before exiting
+const DefaultKeepAliveTime = 60 * time.Second
+
+// Executor runs tasks on its own goroutines
+type Executor interface {
+	// Execute runs the task some time in the future, it returns an error if the task is rejected.
+	Execute(task func()) error
+	// Shutdown stops accepting new tasks, the submitted tasks are still executed.
+	Shutdown()
+	// ShutdownNow stops accepting new tasks and returns the queued tasks of Execute which have not been started,
+	// the futures of the other queued tasks fail or are cancelled. The running tasks are not interrupted.
+	ShutdownNow() []func()
+	// AwaitTermination blocks until all tasks have completed after a shutdown, or the timeout occurs.
+	// It returns true if the executor has terminated.
+	AwaitTermination(timeout time.Duration) bool
+	// IsShutdown returns true if the executor has been shut down
+	IsShutdown() bool
+	// IsTerminated returns true if all tasks have completed after a shutdown
+	IsTerminated() bool
+}
+
+// Submit runs fn on the executor and returns a Future of its result, see NewFutureWithExecutor.
+func Submit[T any](executor Executor, fn func() (t T, err error)) Future[T] {
+	return NewFutureWithExecutor(executor, fn)
+}
+
+// RejectionPolicy decides what a ThreadPoolExecutor does when its workers and queue are full
+type RejectionPolicy int
+
+const (
+	// AbortPolicy rejects the task with ErrRejectedExecution.
+	AbortPolicy RejectionPolicy = iota
+	// CallerRunsPolicy runs the task on the calling goroutine, which slows down the producer.
+	CallerRunsPolicy
+	// DiscardOldestPolicy discards the oldest queued task and queues the task,
+	// the future of the discarded task fails with ErrRejectedExecution.
+	DiscardOldestPolicy
+)
+
+const (
+	executorRunning = iota
+	executorShutdown
+	executorStopped
+)
+
+// task is a queued task, reject is called if the task is discarded, it may be nil
+type task struct {
+	run    func()
+	reject func(err error)
+}
+
+// ThreadPoolExecutor runs tasks on a bounded pool of workers.
+//
+// A new task starts a new worker while there are less than corePoolSize workers,
+// otherwise it is queued. If the queue is full, a new worker is started
+// while there are less than maxPoolSize workers, otherwise the task is rejected by the rejection policy.
+// The workers beyond corePoolSize exit after being idle for the keep-alive time.
+//
+// If a task passed to Execute panics, the program crashes as a panic of a goroutine does,
+// the tasks submitted by Submit or NewFutureWithExecutor fail with the panic instead.
+type ThreadPoolExecutor struct {
+	corePoolSize  int
+	maxPoolSize   int
+	keepAliveTime time.Duration
+	policy        RejectionPolicy
+
+	mu         sync.Mutex
+	state      int
+	workers    int
+	queue      chan task
+	terminated chan struct{}
+
+	active    atomic.Int64
+	completed atomic.Int64
+}
+
+// ExecutorOption configures a ThreadPoolExecutor
+type ExecutorOption func(e *ThreadPoolExecutor)
+
+// WithKeepAliveTime sets the time an idle worker beyond the core pool size waits for a task before exiting,
+// the default is DefaultKeepAliveTime.
+func WithKeepAliveTime(keepAliveTime time.Duration) ExecutorOption {
+	if keepAliveTime <= 0 {
+		panic("keep-alive time must be positive")
+	}
+	return func(e *ThreadPoolExecutor) {
+		e.keepAliveTime = keepAliveTime
+	}
+}
+
+// WithRejectionPolicy sets the rejection policy, the default is AbortPolicy.
+func WithRejectionPolicy(policy RejectionPolicy) ExecutorOption {
+	return func(e *ThreadPoolExecutor) {
+		e.policy = policy
+	}
+}
+
+// NewThreadPoolExecutor returns an executor with at most maxPoolSize workers and a queue of queueCapacity tasks.
+//
+// If queueCapacity is 0, a task is handed off to an idle worker directly, or a new worker is started.
+func NewThreadPoolExecutor(corePoolSize, maxPoolSize, queueCapacity int, opts ...ExecutorOption) *ThreadPoolExecutor {
+	if corePoolSize < 0 {
+		panic("core pool size must not be negative")
+	}
+	if maxPoolSize <= 0 || maxPoolSize < corePoolSize {
+		panic("max pool size must be positive and not less than core pool size")
+	}
+	if queueCapacity < 0 {
+		panic("queue capacity must not be negative")
+	}
+
+	e := &ThreadPoolExecutor{
+		corePoolSize:  corePoolSize,
+		maxPoolSize:   maxPoolSize,
+		keepAliveTime: DefaultKeepAliveTime,
+		policy:        AbortPolicy,
+		queue:         make(chan task, queueCapacity),
+		terminated:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// NewFixedThreadPool returns an executor with n workers and a queue of queueCapacity tasks
+func NewFixedThreadPool(n, queueCapacity int, opts ...ExecutorOption) *ThreadPoolExecutor {
+	return NewThreadPoolExecutor(n, n, queueCapacity, opts...)
+}
+
+func (e *ThreadPoolExecutor) Execute(fn func()) error {
+	return e.execute(task{run: fn})
+}
+
+func (e *ThreadPoolExecutor) execute(t task) error {
+	e.mu.Lock()
+	if e.state != executorRunning {
+		e.mu.Unlock()
+		return ErrRejectedExecution
+	}
+	if e.workers < e.corePoolSize {
+		e.addWorker(&t)
+		e.mu.Unlock()
+		return nil
+	}
+	select {
+	case e.queue <- t:
+		if e.workers == 0 {
+			// corePoolSize is 0, there must be a worker to take the queued task
+			e.addWorker(nil)
+		}
+		e.mu.Unlock()
+		return nil
+	default:
+	}
+	if e.workers < e.maxPoolSize {
+		e.addWorker(&t)
+		e.mu.Unlock()
+		return nil
+	}
+	e.mu.Unlock()
+
+	return e.reject(t)
+}
+
+// reject handles the task by the rejection policy, it must be called without holding the lock
+func (e *ThreadPoolExecutor) reject(t task) error {
+	switch e.policy {
+	case CallerRunsPolicy:
+		t.run()
+		return nil
+	case DiscardOldestPolicy:
+		e.mu.Lock()
+		if e.state != executorRunning {
+			e.mu.Unlock()
+			return ErrRejectedExecution
+		}
+		var discarded *task
+		select {
+		case oldest := <-e.queue:
+			discarded = &oldest
+		default:
+		}
+		var err error
+		select {
+		case e.queue <- t:
+		default:
+			// the queue has no capacity to discard
+			err = ErrRejectedExecution
+		}
+		e.mu.Unlock()
+
+		if discarded != nil && discarded.reject != nil {
+			discarded.reject(ErrRejectedExecution)
+		}
+		return err
+	default:
+		return ErrRejectedExecution
+	}
+}
+
+// addWorker starts a worker with the first task, it must be called with the lock held
+func (e *ThreadPoolExecutor) addWorker(first *task) {
+	e.workers++
+	go e.work(first)
+}
+
+func (e *ThreadPoolExecutor) work(first *task) {
+	if first != nil {
+		e.runTask(*first)
+	}
+
+	timer := time.NewTimer(e.keepAliveTime)
+	defer timer.Stop()
+	for {
+		select {
+		case t, ok := <-e.queue:
+			if !ok {
+				e.exitWorker()
+				return
+			}
+			e.runTask(t)
+		case <-timer.C:
+			e.mu.Lock()
+			if e.workers > e.corePoolSize && len(e.queue) == 0 {
+				e.exitWorkerLocked()
+				e.mu.Unlock()
+				return
+			}
+			e.mu.Unlock()
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(e.keepAliveTime)
+	}
+}
+
+func (e *ThreadPoolExecutor) runTask(t task) {
+	e.active.Add(1)
+	defer func() {
+		e.active.Add(-1)
+		e.completed.Add(1)
+	}()
+	t.run()
+}
+
+func (e *ThreadPoolExecutor) exitWorker() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exitWorkerLocked()
+}
+
+// exitWorkerLocked removes the worker, it must be called with the lock held
+func (e *ThreadPoolExecutor) exitWorkerLocked() {
+	e.workers--
+	if e.workers == 0 && e.state != executorRunning {
+		e.terminate()
+	}
+}
+
+// terminate marks the executor terminated, it must be called with the lock held
+func (e *ThreadPoolExecutor) terminate() {
+	select {
+	case <-e.terminated:
+	default:
+		close(e.terminated)
+	}
+}
+
+func (e *ThreadPoolExecutor) Shutdown() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.state != executorRunning {
+		return
+	}
+	e.state = executorShutdown
+	close(e.queue)
+	if e.workers == 0 {
+		e.terminate()
+	}
+}
+
+// ShutdownNow drains the queue, the futures of the tasks submitted by Submit or NewFutureWithExecutor
+// fail with ErrRejectedExecution, and the tasks of Execute are returned.
+func (e *ThreadPoolExecutor) ShutdownNow() []func() {
+	e.mu.Lock()
+	if e.state == executorRunning {
+		close(e.queue)
+		if e.workers == 0 {
+			e.terminate()
+		}
+	}
+	e.state = executorStopped
+	var drained []task
+	for t := range e.queue {
+		drained = append(drained, t)
+	}
+	e.mu.Unlock()
+
+	// the futures are failed without holding the lock, since their callbacks may use the executor
+	var tasks []func()
+	for _, t := range drained {
+		if t.reject != nil {
+			t.reject(ErrRejectedExecution)
+		} else {
+			tasks = append(tasks, t.run)
+		}
+	}
+	return tasks
+}
+
+func (e *ThreadPoolExecutor) AwaitTermination(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-e.terminated:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (e *ThreadPoolExecutor) IsShutdown() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.state != executorRunning
+}
+
+func (e *ThreadPoolExecutor) IsTerminated() bool {
+	select {
+	case <-e.terminated:
+		return true
+	default:
+		return false
+	}
+}
+
+// PoolSize returns the current number of workers
+func (e *ThreadPoolExecutor) PoolSize() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.workers
+}
+
+// ActiveCount returns the number of workers running tasks
+func (e *ThreadPoolExecutor) ActiveCount() int {
+	return int(e.active.Load())
+}
+
+// QueueSize returns the number of queued tasks
+func (e *ThreadPoolExecutor) QueueSize() int {
+	return len(e.queue)
+}
+
+// CompletedTaskCount returns the number of completed tasks
+func (e *ThreadPoolExecutor) CompletedTaskCount() int64 {
+	return e.completed.Load()
+}
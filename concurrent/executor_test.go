@@ -0,0 +1,156 @@
+package concurrent
+
+import (
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThreadPoolExecutor_Submit(t *testing.T) {
+	e := NewFixedThreadPool(4, 100)
+	futures := make([]Future[int], 0, 100)
+	for i := 0; i < 100; i++ {
+		futures = append(futures, Submit(e, func(i int) func() (int, error) {
+			return func() (int, error) {
+				return i * i, nil
+			}
+		}(i)))
+	}
+	for i, f := range futures {
+		v, err := f.Get()
+		require.NoError(t, err)
+		require.Equal(t, i*i, v)
+	}
+	require.LessOrEqual(t, e.PoolSize(), 4)
+
+	e.Shutdown()
+	require.True(t, e.AwaitTermination(time.Second))
+	require.True(t, e.IsShutdown())
+	require.True(t, e.IsTerminated())
+	require.Equal(t, int64(100), e.CompletedTaskCount())
+
+	_, err := Submit(e, func() (int, error) { return 0, nil }).Get()
+	require.ErrorIs(t, err, ErrRejectedExecution)
+}
+
+func TestThreadPoolExecutor_Panic(t *testing.T) {
+	e := NewFixedThreadPool(1, 1)
+	defer e.Shutdown()
+
+	_, err := NewFutureWithExecutor(e, func() (int, error) {
+		panic("boom")
+	}).Get()
+	require.ErrorContains(t, err, "boom")
+
+	// the worker survives the panic
+	v, err := Submit(e, func() (int, error) { return 1, nil }).Get()
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+}
+
+func TestThreadPoolExecutor_Bounded(t *testing.T) {
+	release := make(chan struct{})
+	block := func() { <-release }
+
+	e := NewThreadPoolExecutor(1, 2, 1)
+	require.NoError(t, e.Execute(block)) // starts the core worker
+	require.NoError(t, e.Execute(block)) // queued
+	require.NoError(t, e.Execute(block)) // starts the second worker
+	require.ErrorIs(t, e.Execute(block), ErrRejectedExecution)
+	require.Equal(t, 2, e.PoolSize())
+	require.Equal(t, 1, e.QueueSize())
+
+	close(release)
+	e.Shutdown()
+	require.True(t, e.AwaitTermination(time.Second))
+	require.Equal(t, int64(3), e.CompletedTaskCount())
+}
+
+func TestThreadPoolExecutor_CallerRunsPolicy(t *testing.T) {
+	release := make(chan struct{})
+	e := NewThreadPoolExecutor(1, 1, 0, WithRejectionPolicy(CallerRunsPolicy))
+	require.NoError(t, e.Execute(func() { <-release }))
+
+	ran := false
+	require.NoError(t, e.Execute(func() { ran = true }))
+	require.True(t, ran)
+
+	close(release)
+	e.Shutdown()
+	require.True(t, e.AwaitTermination(time.Second))
+}
+
+func TestThreadPoolExecutor_DiscardOldestPolicy(t *testing.T) {
+	release := make(chan struct{})
+	e := NewThreadPoolExecutor(1, 1, 1, WithRejectionPolicy(DiscardOldestPolicy))
+	require.NoError(t, e.Execute(func() { <-release }))
+
+	oldest := Submit(e, func() (int, error) { return 1, nil })
+	newest := Submit(e, func() (int, error) { return 2, nil })
+
+	_, err := oldest.Get()
+	require.ErrorIs(t, err, ErrRejectedExecution)
+	close(release)
+	v, err := newest.Get()
+	require.NoError(t, err)
+	require.Equal(t, 2, v)
+
+	e.Shutdown()
+	require.True(t, e.AwaitTermination(time.Second))
+}
+
+func TestThreadPoolExecutor_ShutdownNow(t *testing.T) {
+	release := make(chan struct{})
+	e := NewFixedThreadPool(1, 10)
+	require.NoError(t, e.Execute(func() { <-release }))
+	var executed atomic.Int64
+	for i := 0; i < 5; i++ {
+		require.NoError(t, e.Execute(func() { executed.Add(1) }))
+	}
+
+	submitted := Submit(e, func() (int, error) { return 1, nil })
+
+	tasks := e.ShutdownNow()
+	require.Len(t, tasks, 5)
+	require.False(t, e.AwaitTermination(10*time.Millisecond))
+	_, err := submitted.Get()
+	require.ErrorIs(t, err, ErrRejectedExecution)
+
+	close(release)
+	require.True(t, e.AwaitTermination(time.Second))
+	require.Equal(t, int64(0), executed.Load())
+	for _, task := range tasks {
+		task()
+	}
+	require.Equal(t, int64(5), executed.Load())
+}
+
+func TestThreadPoolExecutor_KeepAlive(t *testing.T) {
+	e := NewThreadPoolExecutor(0, 4, 0, WithKeepAliveTime(10*time.Millisecond))
+	var wg sync.WaitGroup
+	wg.Add(4)
+	release := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		require.NoError(t, e.Execute(func() {
+			defer wg.Done()
+			<-release
+		}))
+	}
+	require.Equal(t, 4, e.PoolSize())
+	close(release)
+	wg.Wait()
+
+	require.Eventually(t, func() bool {
+		return e.PoolSize() == 0
+	}, time.Second, 5*time.Millisecond)
+
+	// a new task starts a new worker
+	v, err := Submit(e, func() (int, error) { return 1, nil }).Get()
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+
+	e.Shutdown()
+	require.True(t, e.AwaitTermination(time.Second))
+}
@@ -1,15 +1,31 @@
 package concurrent
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"sync"
-	"sync/atomic"
 	"time"
-	"unsafe"
 )
 
+// ErrCancelled is returned by a Future which has been cancelled
+var ErrCancelled = errors.New("concurrent: future cancelled")
+
 type Future[T any] interface {
 	Get() (t T, err error)
 	GetWithTimeout(timeout time.Duration) (t T, isTimeout bool, err error)
+	// GetContext waits for the result until the context is done, then it returns the error of the context.
+	GetContext(ctx context.Context) (t T, err error)
+	// Done returns a channel which is closed when the future completes, so that it can be used in select.
+	Done() <-chan struct{}
+	// IsDone returns true if the future has completed, successfully, exceptionally or by cancellation.
+	IsDone() bool
+	// Cancel completes the future with ErrCancelled if it has not completed,
+	// and cancels the context of the function if the future is created by NewFutureWithContext.
+	// It returns true if the future is cancelled by this call.
+	Cancel() bool
+	// IsCancelled returns true if the future has been cancelled
+	IsCancelled() bool
 }
 
 type holder[T any] struct {
@@ -18,40 +34,171 @@ type holder[T any] struct {
 }
 
 type future[T any] struct {
-	h  *holder[T]
-	wg *sync.WaitGroup
+	h         holder[T]
+	done      chan struct{}
+	once      sync.Once
+	cancelled bool
+	// cancelCtx cancels the context of the function, it may be nil
+	cancelCtx context.CancelFunc
+
+	mu        sync.Mutex
+	completed bool
+	callbacks []func(t T, err error)
 }
 
 func NewFuture[T any](fn func() (t T, err error)) Future[T] {
-	f := &future[T]{
-		wg: new(sync.WaitGroup),
-	}
-	f.wg.Add(1)
+	f := newFuture[T]()
 	go func() {
-		defer f.wg.Done()
+		f.complete(fn())
+	}()
+	return f
+}
 
-		t, err := fn()
-		h := &holder[T]{t, err}
-		atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&f.h)), unsafe.Pointer(h))
+// NewFutureWithContext is like NewFuture, but fn receives a context derived from ctx,
+// which is cancelled when the future is cancelled or completes.
+func NewFutureWithContext[T any](ctx context.Context, fn func(ctx context.Context) (t T, err error)) Future[T] {
+	f := newFuture[T]()
+	ctx, f.cancelCtx = context.WithCancel(ctx)
+	go func() {
+		defer f.cancelCtx()
+		f.complete(fn(ctx))
 	}()
 	return f
 }
 
+// NewFutureWithExecutor is like NewFuture, but fn runs on the executor instead of a new goroutine.
+//
+// If the executor rejects fn, the future fails with the rejection error.
+// If fn panics, the future fails with an error describing the panic.
+// If the future is cancelled before fn starts, fn does not run.
+func NewFutureWithExecutor[T any](executor Executor, fn func() (t T, err error)) Future[T] {
+	f := newFuture[T]()
+	run := func() {
+		if f.IsDone() {
+			return
+		}
+		f.complete(callSafely(fn))
+	}
+	fail := func(err error) {
+		var zero T
+		f.complete(zero, err)
+	}
+
+	var err error
+	if tpe, ok := executor.(*ThreadPoolExecutor); ok {
+		err = tpe.execute(task{run: run, reject: fail})
+	} else {
+		err = executor.Execute(run)
+	}
+	if err != nil {
+		fail(err)
+	}
+	return f
+}
+
+func newFuture[T any]() *future[T] {
+	return &future[T]{
+		done: make(chan struct{}),
+	}
+}
+
+// complete sets the result of the future and runs the callbacks,
+// only the first call takes effect and returns true.
+func (f *future[T]) complete(t T, err error) bool {
+	return f.settle(t, err, false)
+}
+
+// settle completes the future, cancelled is set before done is closed,
+// so that it is visible to whoever sees the future done.
+func (f *future[T]) settle(t T, err error, cancelled bool) (completed bool) {
+	f.once.Do(func() {
+		f.h = holder[T]{t, err}
+		f.cancelled = cancelled
+		close(f.done)
+
+		f.mu.Lock()
+		f.completed = true
+		callbacks := f.callbacks
+		f.callbacks = nil
+		f.mu.Unlock()
+		for _, callback := range callbacks {
+			callback(t, err)
+		}
+		completed = true
+	})
+	return completed
+}
+
+// onComplete calls the callback with the result once the future completes,
+// the callback runs on the completing goroutine, or on the calling goroutine if the future has completed.
+func (f *future[T]) onComplete(callback func(t T, err error)) {
+	f.mu.Lock()
+	if !f.completed {
+		f.callbacks = append(f.callbacks, callback)
+		f.mu.Unlock()
+		return
+	}
+	f.mu.Unlock()
+	callback(f.h.t, f.h.err)
+}
+
+// callSafely calls fn and turns its panic into an error
+func callSafely[T any](fn func() (T, error)) (t T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("concurrent: task panicked: %v", r)
+		}
+	}()
+	return fn()
+}
+
 func (f *future[T]) Get() (t T, err error) {
-	f.wg.Wait()
+	<-f.done
 	return f.h.t, f.h.err
 }
 
 func (f *future[T]) GetWithTimeout(timeout time.Duration) (t T, isTimeout bool, err error) {
-	ch := make(chan int, 1)
-	go func() {
-		f.wg.Wait()
-		ch <- 0
-	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 	select {
-	case <-ch:
+	case <-f.done:
 		return f.h.t, false, f.h.err
-	case <-time.After(timeout):
+	case <-timer.C:
 		return t, true, nil
 	}
 }
+
+func (f *future[T]) GetContext(ctx context.Context) (t T, err error) {
+	select {
+	case <-f.done:
+		return f.h.t, f.h.err
+	case <-ctx.Done():
+		return t, ctx.Err()
+	}
+}
+
+func (f *future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+func (f *future[T]) IsDone() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f *future[T]) Cancel() bool {
+	var zero T
+	cancelled := f.settle(zero, ErrCancelled, true)
+	if f.cancelCtx != nil {
+		f.cancelCtx()
+	}
+	return cancelled
+}
+
+func (f *future[T]) IsCancelled() bool {
+	return f.IsDone() && f.cancelled
+}
@@ -1,6 +1,9 @@
 package concurrent
 
 import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"runtime"
 	"testing"
 	"time"
 )
@@ -36,3 +39,74 @@ func TestFuture_GetWithTimeout(t *testing.T) {
 
 	time.Sleep(5 * time.Second)
 }
+
+func TestFuture_GetWithTimeoutNoLeak(t *testing.T) {
+	p := NewPromise[int]()
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		_, timeout, err := p.GetWithTimeout(time.Millisecond)
+		require.True(t, timeout)
+		require.NoError(t, err)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestFuture_GetContext(t *testing.T) {
+	f := NewFuture(func() (int, error) {
+		time.Sleep(50 * time.Millisecond)
+		return 1, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := f.GetContext(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, f.IsDone())
+
+	v, err := f.GetContext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+	require.True(t, f.IsDone())
+	require.False(t, f.IsCancelled())
+	require.False(t, f.Cancel())
+}
+
+func TestFuture_Cancel(t *testing.T) {
+	ctxDone := make(chan struct{})
+	f := NewFutureWithContext(context.Background(), func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(ctxDone)
+		return 0, ctx.Err()
+	})
+	require.False(t, f.IsDone())
+	require.True(t, f.Cancel())
+	require.False(t, f.Cancel())
+
+	select {
+	case <-f.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the cancelled future must be done")
+	}
+	<-ctxDone
+	require.True(t, f.IsDone())
+	require.True(t, f.IsCancelled())
+	_, err := f.Get()
+	require.ErrorIs(t, err, ErrCancelled)
+}
+
+func TestFuture_CancelBeforeExecution(t *testing.T) {
+	release := make(chan struct{})
+	e := NewFixedThreadPool(1, 1)
+	require.NoError(t, e.Execute(func() { <-release }))
+
+	ran := false
+	f := Submit(e, func() (int, error) {
+		ran = true
+		return 1, nil
+	})
+	require.True(t, f.Cancel())
+	close(release)
+	e.Shutdown()
+	require.True(t, e.AwaitTermination(time.Second))
+	require.False(t, ran)
+	require.True(t, f.IsCancelled())
+}
@@ -0,0 +1,485 @@
+package concurrent
+
+import (
+	"container/heap"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ScheduledFuture is a Future of a delayed or periodic task.
+//
+// The Future of a periodic task never completes successfully, it completes when the task is cancelled,
+// when the task fails or panics, which also stops the task, or when the executor is shut down.
+type ScheduledFuture[T any] interface {
+	Future[T]
+	// Delay returns the remaining time until the next run of the task, it is 0 if the task is running or done.
+	Delay() time.Duration
+}
+
+// ScheduledExecutor runs tasks after a delay or periodically.
+//
+// The tasks wait in a timer heap, which is served by one goroutine,
+// and run on new goroutines, or on the executor given by WithTaskExecutor.
+// A periodic task never overlaps with itself, its next run is scheduled after the current run completes.
+type ScheduledExecutor struct {
+	clock    Clock
+	executor Executor
+
+	mu       sync.Mutex
+	queue    scheduledQueue
+	shutdown bool
+	wakeup   chan struct{}
+	// running tracks the scheduler goroutine and the running tasks
+	running    sync.WaitGroup
+	terminated chan struct{}
+}
+
+// ScheduledExecutorOption configures a ScheduledExecutor
+type ScheduledExecutorOption func(s *ScheduledExecutor)
+
+// WithClock sets the clock of the executor, the default is SystemClock.
+func WithClock(clock Clock) ScheduledExecutorOption {
+	return func(s *ScheduledExecutor) {
+		s.clock = clock
+	}
+}
+
+// WithTaskExecutor sets the executor which runs the due tasks, by default each run starts a new goroutine.
+func WithTaskExecutor(executor Executor) ScheduledExecutorOption {
+	return func(s *ScheduledExecutor) {
+		s.executor = executor
+	}
+}
+
+// ScheduleOption configures a scheduled task
+type ScheduleOption func(t *scheduledTask)
+
+// WithJitter adds a random delay in [0, jitter) to each run of the task,
+// which spreads the runs of many tasks scheduled at the same time.
+func WithJitter(jitter time.Duration) ScheduleOption {
+	if jitter < 0 {
+		panic("jitter must not be negative")
+	}
+	return func(t *scheduledTask) {
+		t.jitter = jitter
+	}
+}
+
+// NewScheduledExecutor returns a started ScheduledExecutor
+func NewScheduledExecutor(opts ...ScheduledExecutorOption) *ScheduledExecutor {
+	s := &ScheduledExecutor{
+		clock:      SystemClock,
+		wakeup:     make(chan struct{}, 1),
+		terminated: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.running.Add(1)
+	go s.loop()
+	go func() {
+		s.running.Wait()
+		close(s.terminated)
+	}()
+	return s
+}
+
+// Schedule runs fn once after the delay.
+//
+// If the executor has been shut down, the returned Future fails with ErrRejectedExecution.
+func Schedule[T any](s *ScheduledExecutor, delay time.Duration, fn func() (t T, err error),
+	opts ...ScheduleOption) ScheduledFuture[T] {
+	f := &scheduledFuture[T]{future: newFuture[T](), executor: s}
+	t := &scheduledTask{
+		index: -1,
+		run: func(*scheduledTask) {
+			if !f.IsDone() {
+				f.complete(callSafely(fn))
+			}
+		},
+		cancel: func(err error) {
+			var zero T
+			f.settle(zero, err, err == ErrCancelled)
+		},
+	}
+	f.task = t
+	for _, opt := range opts {
+		opt(t)
+	}
+	if err := s.schedule(t, s.clock.Now().Add(delay)); err != nil {
+		t.cancel(err)
+	}
+	return f
+}
+
+// ScheduleAtFixedRate runs fn periodically, the first run is after initialDelay,
+// and the following runs start every period since the first one.
+// If a run takes longer than the period, the next run starts right after it.
+func (s *ScheduledExecutor) ScheduleAtFixedRate(initialDelay, period time.Duration, fn func() error,
+	opts ...ScheduleOption) ScheduledFuture[struct{}] {
+	if period <= 0 {
+		panic("period must be positive")
+	}
+	return s.schedulePeriodic(s.clock.Now().Add(initialDelay), fn, func(scheduled, _ time.Time) time.Time {
+		return scheduled.Add(period)
+	}, opts)
+}
+
+// ScheduleWithFixedDelay runs fn periodically, the first run is after initialDelay,
+// and each following run starts delay after the previous one completes.
+func (s *ScheduledExecutor) ScheduleWithFixedDelay(initialDelay, delay time.Duration, fn func() error,
+	opts ...ScheduleOption) ScheduledFuture[struct{}] {
+	if delay <= 0 {
+		panic("delay must be positive")
+	}
+	return s.schedulePeriodic(s.clock.Now().Add(initialDelay), fn, func(_, completed time.Time) time.Time {
+		return completed.Add(delay)
+	}, opts)
+}
+
+// ScheduleCron runs fn at the times matching the cron expression in the location of the clock, see CronSchedule.
+// The runs missed while the previous run is running are skipped.
+func (s *ScheduledExecutor) ScheduleCron(expr string, fn func() error,
+	opts ...ScheduleOption) (ScheduledFuture[struct{}], error) {
+	cron, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	next := func(scheduled, completed time.Time) time.Time {
+		if completed.After(scheduled) {
+			return cron.Next(completed)
+		}
+		return cron.Next(scheduled)
+	}
+	return s.schedulePeriodic(next(time.Time{}, s.clock.Now()), fn, next, opts), nil
+}
+
+func (s *ScheduledExecutor) schedulePeriodic(first time.Time, fn func() error,
+	next func(scheduled, completed time.Time) time.Time, opts []ScheduleOption) ScheduledFuture[struct{}] {
+	f := &scheduledFuture[struct{}]{future: newFuture[struct{}](), executor: s}
+	t := &scheduledTask{
+		index:    -1,
+		periodic: true,
+		cancel: func(err error) {
+			f.settle(struct{}{}, err, err == ErrCancelled)
+		},
+	}
+	t.run = func(t *scheduledTask) {
+		if f.IsDone() {
+			return
+		}
+		if _, err := callSafely(func() (struct{}, error) {
+			return struct{}{}, fn()
+		}); err != nil {
+			f.complete(struct{}{}, err)
+			return
+		}
+		// the task may be cancelled while it is running
+		if f.IsDone() {
+			return
+		}
+		at := next(t.scheduled, s.clock.Now())
+		if at.IsZero() {
+			// the cron expression never matches again
+			f.complete(struct{}{}, nil)
+			return
+		}
+		if err := s.schedule(t, at); err != nil {
+			// the executor has been shut down
+			t.cancel(ErrCancelled)
+			return
+		}
+		if f.IsDone() {
+			s.remove(t)
+		}
+	}
+	f.task = t
+	for _, opt := range opts {
+		opt(t)
+	}
+	if first.IsZero() {
+		f.complete(struct{}{}, nil)
+		return f
+	}
+	if err := s.schedule(t, first); err != nil {
+		t.cancel(err)
+	}
+	return f
+}
+
+// schedule adds the task to the timer heap at the time plus the jitter,
+// it returns ErrRejectedExecution if the executor has been shut down.
+func (s *ScheduledExecutor) schedule(t *scheduledTask, at time.Time) error {
+	t.scheduled = at
+	if t.jitter > 0 {
+		at = at.Add(time.Duration(rand.Int63n(int64(t.jitter))))
+	}
+
+	s.mu.Lock()
+	if s.shutdown {
+		s.mu.Unlock()
+		return ErrRejectedExecution
+	}
+	t.at = at
+	heap.Push(&s.queue, t)
+	first := s.queue[0] == t
+	s.mu.Unlock()
+
+	if first {
+		s.wake()
+	}
+	return nil
+}
+
+// remove removes the task from the timer heap, it returns false if the task is not in the heap
+func (s *ScheduledExecutor) remove(t *scheduledTask) bool {
+	s.mu.Lock()
+	if t.index < 0 {
+		s.mu.Unlock()
+		return false
+	}
+	heap.Remove(&s.queue, t.index)
+	s.mu.Unlock()
+	// the loop may exit if the executor has been shut down and the heap is empty
+	s.wake()
+	return true
+}
+
+func (s *ScheduledExecutor) wake() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// loop runs the due tasks and sleeps until the earliest task is due
+func (s *ScheduledExecutor) loop() {
+	defer s.running.Done()
+	for {
+		s.mu.Lock()
+		if s.shutdown && len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		var timer Timer
+		if len(s.queue) > 0 {
+			now := s.clock.Now()
+			if t := s.queue[0]; !now.Before(t.at) {
+				heap.Pop(&s.queue)
+				s.mu.Unlock()
+				s.dispatch(t)
+				continue
+			}
+			timer = s.clock.NewTimer(s.queue[0].at.Sub(now))
+			// the clock may have passed the time before the timer is created
+			if !s.clock.Now().Before(s.queue[0].at) {
+				timer.Stop()
+				s.mu.Unlock()
+				continue
+			}
+		}
+		s.mu.Unlock()
+
+		if timer == nil {
+			<-s.wakeup
+			continue
+		}
+		select {
+		case <-timer.C():
+		case <-s.wakeup:
+			timer.Stop()
+		}
+	}
+}
+
+// dispatch runs the task on the executor
+func (s *ScheduledExecutor) dispatch(t *scheduledTask) {
+	s.running.Add(1)
+	run := func() {
+		defer s.running.Done()
+		t.run(t)
+	}
+	if s.executor == nil {
+		go run()
+		return
+	}
+	if err := s.executor.Execute(run); err != nil {
+		s.running.Done()
+		if t.cancel != nil {
+			t.cancel(err)
+		}
+	}
+}
+
+// Execute runs the task as soon as possible
+func (s *ScheduledExecutor) Execute(fn func()) error {
+	return s.schedule(&scheduledTask{
+		index: -1,
+		run: func(*scheduledTask) {
+			fn()
+		},
+	}, s.clock.Now())
+}
+
+// Shutdown stops accepting new tasks, the pending tasks of Execute and Schedule still run at their times,
+// while the periodic and cron tasks are cancelled, the running tasks are not interrupted.
+func (s *ScheduledExecutor) Shutdown() {
+	s.mu.Lock()
+	if s.shutdown {
+		s.mu.Unlock()
+		return
+	}
+	s.shutdown = true
+	var periodic []*scheduledTask
+	pending := s.queue[:0]
+	for _, t := range s.queue {
+		if t.periodic {
+			t.index = -1
+			periodic = append(periodic, t)
+		} else {
+			pending = append(pending, t)
+		}
+	}
+	for i := len(pending); i < len(s.queue); i++ {
+		s.queue[i] = nil
+	}
+	s.queue = pending
+	heap.Init(&s.queue)
+	s.mu.Unlock()
+	s.wake()
+
+	for _, t := range periodic {
+		t.cancel(ErrCancelled)
+	}
+}
+
+// ShutdownNow stops accepting new tasks and cancels the futures of all pending tasks,
+// it returns the pending tasks of Execute, which have no futures and have not been started.
+// The running tasks are not interrupted.
+func (s *ScheduledExecutor) ShutdownNow() []func() {
+	s.mu.Lock()
+	s.shutdown = true
+	pending := s.queue
+	s.queue = nil
+	for _, t := range pending {
+		t.index = -1
+	}
+	s.mu.Unlock()
+	s.wake()
+
+	var tasks []func()
+	for _, t := range pending {
+		if t.cancel == nil {
+			tasks = append(tasks, func(t *scheduledTask) func() {
+				return func() {
+					t.run(t)
+				}
+			}(t))
+		} else {
+			t.cancel(ErrCancelled)
+		}
+	}
+	return tasks
+}
+
+func (s *ScheduledExecutor) AwaitTermination(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-s.terminated:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (s *ScheduledExecutor) IsShutdown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shutdown
+}
+
+func (s *ScheduledExecutor) IsTerminated() bool {
+	select {
+	case <-s.terminated:
+		return true
+	default:
+		return false
+	}
+}
+
+// scheduledTask is a task in the timer heap
+type scheduledTask struct {
+	// at is the time to run, it is scheduled plus the jitter
+	at        time.Time
+	scheduled time.Time
+	jitter    time.Duration
+	index     int
+	periodic  bool
+	run       func(t *scheduledTask)
+	// cancel completes the future of the task with the error, it is nil for the tasks of Execute
+	cancel func(err error)
+}
+
+// scheduledQueue is a min-heap of tasks ordered by their time
+type scheduledQueue []*scheduledTask
+
+func (q scheduledQueue) Len() int {
+	return len(q)
+}
+
+func (q scheduledQueue) Less(i, j int) bool {
+	return q[i].at.Before(q[j].at)
+}
+
+func (q scheduledQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scheduledQueue) Push(x any) {
+	t := x.(*scheduledTask)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *scheduledQueue) Pop() any {
+	old := *q
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	t.index = -1
+	*q = old[:len(old)-1]
+	return t
+}
+
+type scheduledFuture[T any] struct {
+	*future[T]
+	executor *ScheduledExecutor
+	task     *scheduledTask
+}
+
+func (f *scheduledFuture[T]) Cancel() bool {
+	cancelled := f.future.Cancel()
+	if cancelled {
+		f.executor.remove(f.task)
+	}
+	return cancelled
+}
+
+func (f *scheduledFuture[T]) Delay() time.Duration {
+	if f.IsDone() {
+		return 0
+	}
+	f.executor.mu.Lock()
+	defer f.executor.mu.Unlock()
+	if f.task.index < 0 {
+		return 0
+	}
+	if delay := f.task.at.Sub(f.executor.clock.Now()); delay > 0 {
+		return delay
+	}
+	return 0
+}
@@ -0,0 +1,225 @@
+package concurrent
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fakeEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func TestScheduledExecutor_Schedule(t *testing.T) {
+	clock := NewFakeClock(fakeEpoch)
+	s := NewScheduledExecutor(WithClock(clock))
+	defer s.Shutdown()
+
+	late := Schedule(s, 2*time.Second, func() (int, error) { return 2, nil })
+	early := Schedule(s, time.Second, func() (int, error) { return 1, nil })
+	require.Equal(t, 2*time.Second, late.Delay())
+	require.Equal(t, time.Second, early.Delay())
+
+	clock.Advance(time.Second)
+	v, err := early.Get()
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+	require.False(t, late.IsDone())
+
+	clock.Advance(time.Second)
+	v, err = late.Get()
+	require.NoError(t, err)
+	require.Equal(t, 2, v)
+}
+
+func TestScheduledExecutor_Cancel(t *testing.T) {
+	clock := NewFakeClock(fakeEpoch)
+	s := NewScheduledExecutor(WithClock(clock))
+	defer s.Shutdown()
+
+	var ran atomic.Int64
+	f := Schedule(s, time.Second, func() (int, error) {
+		ran.Add(1)
+		return 1, nil
+	})
+	require.True(t, f.Cancel())
+	require.True(t, f.IsCancelled())
+	require.Equal(t, time.Duration(0), f.Delay())
+
+	clock.Advance(time.Second)
+	_, err := Schedule(s, 0, func() (int, error) { return 0, nil }).Get()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), ran.Load())
+}
+
+func TestScheduledExecutor_ScheduleAtFixedRate(t *testing.T) {
+	clock := NewFakeClock(fakeEpoch)
+	s := NewScheduledExecutor(WithClock(clock))
+	defer s.Shutdown()
+
+	ran := make(chan time.Time)
+	f := s.ScheduleAtFixedRate(time.Second, time.Second, func() error {
+		ran <- clock.Now()
+		return nil
+	})
+	for i := 1; i <= 3; i++ {
+		clock.Advance(time.Second)
+		require.Equal(t, fakeEpoch.Add(time.Duration(i)*time.Second), <-ran)
+	}
+	require.True(t, f.Cancel())
+	_, err := f.Get()
+	require.ErrorIs(t, err, ErrCancelled)
+}
+
+func TestScheduledExecutor_ScheduleWithFixedDelay(t *testing.T) {
+	clock := NewFakeClock(fakeEpoch)
+	s := NewScheduledExecutor(WithClock(clock))
+	defer s.Shutdown()
+
+	var runs atomic.Int64
+	f := s.ScheduleWithFixedDelay(0, time.Minute, func() error {
+		if runs.Add(1) == 3 {
+			return errors.New("stop")
+		}
+		return nil
+	})
+	for i := int64(1); i <= 2; i++ {
+		require.Eventually(t, func() bool {
+			return runs.Load() == i && f.Delay() == time.Minute
+		}, time.Second, time.Millisecond)
+		clock.Advance(time.Minute)
+	}
+	_, err := f.Get()
+	require.EqualError(t, err, "stop")
+	require.Equal(t, int64(3), runs.Load())
+}
+
+func TestScheduledExecutor_ScheduleCron(t *testing.T) {
+	clock := NewFakeClock(fakeEpoch)
+	s := NewScheduledExecutor(WithClock(clock))
+	defer s.Shutdown()
+
+	_, err := s.ScheduleCron("61 * * * *", func() error { return nil })
+	require.Error(t, err)
+
+	ran := make(chan time.Time)
+	f, err := s.ScheduleCron("*/15 * * * *", func() error {
+		ran <- clock.Now()
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 15*time.Minute, f.Delay())
+
+	clock.Advance(15 * time.Minute)
+	require.Equal(t, fakeEpoch.Add(15*time.Minute), <-ran)
+	require.Eventually(t, func() bool {
+		return f.Delay() == 15*time.Minute
+	}, time.Second, time.Millisecond)
+	clock.Advance(15 * time.Minute)
+	require.Equal(t, fakeEpoch.Add(30*time.Minute), <-ran)
+}
+
+func TestScheduledExecutor_Jitter(t *testing.T) {
+	clock := NewFakeClock(fakeEpoch)
+	s := NewScheduledExecutor(WithClock(clock))
+	defer s.Shutdown()
+
+	for i := 0; i < 10; i++ {
+		f := Schedule(s, time.Second, func() (int, error) { return 0, nil }, WithJitter(time.Second))
+		require.GreaterOrEqual(t, f.Delay(), time.Second)
+		require.Less(t, f.Delay(), 2*time.Second)
+	}
+}
+
+func TestScheduledExecutor_Shutdown(t *testing.T) {
+	e := NewFixedThreadPool(2, 10)
+	defer e.Shutdown()
+	s := NewScheduledExecutor(WithTaskExecutor(e))
+
+	v, err := Schedule(s, time.Millisecond, func() (int, error) { return 1, nil }).Get()
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+
+	pending := Schedule(s, time.Hour, func() (int, error) { return 0, nil })
+	require.Empty(t, s.ShutdownNow())
+	require.True(t, s.IsShutdown())
+	require.True(t, s.AwaitTermination(time.Second))
+	require.True(t, s.IsTerminated())
+	require.True(t, pending.IsCancelled())
+
+	_, err = Schedule(s, 0, func() (int, error) { return 0, nil }).Get()
+	require.ErrorIs(t, err, ErrRejectedExecution)
+	require.ErrorIs(t, s.Execute(func() {}), ErrRejectedExecution)
+}
+
+func TestScheduledExecutor_ShutdownRunsPendingTasks(t *testing.T) {
+	clock := NewFakeClock(fakeEpoch)
+	s := NewScheduledExecutor(WithClock(clock))
+
+	executed := make(chan struct{})
+	require.NoError(t, s.Execute(func() { close(executed) }))
+	delayed := Schedule(s, time.Minute, func() (int, error) { return 1, nil })
+	periodic := s.ScheduleAtFixedRate(time.Second, time.Second, func() error { return nil })
+	s.Shutdown()
+
+	<-executed
+	require.True(t, periodic.IsCancelled())
+	require.False(t, delayed.IsDone())
+	require.False(t, s.AwaitTermination(10*time.Millisecond))
+
+	clock.Advance(time.Minute)
+	v, err := delayed.Get()
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+	require.True(t, s.AwaitTermination(time.Second))
+}
+
+func TestScheduledExecutor_ShutdownNow(t *testing.T) {
+	clock := NewFakeClock(fakeEpoch)
+	s := NewScheduledExecutor(WithClock(clock))
+
+	// a task of Execute which is not due yet
+	var executed atomic.Int64
+	require.NoError(t, s.schedule(&scheduledTask{
+		index: -1,
+		run: func(*scheduledTask) {
+			executed.Add(1)
+		},
+	}, clock.Now().Add(time.Minute)))
+	delayed := Schedule(s, time.Minute, func() (int, error) { return 1, nil })
+	tasks := s.ShutdownNow()
+
+	require.Len(t, tasks, 1)
+	tasks[0]()
+	require.Equal(t, int64(1), executed.Load())
+	require.True(t, delayed.IsCancelled())
+	require.True(t, s.AwaitTermination(time.Second))
+}
+
+func TestScheduledExecutor_CancelWhileRunning(t *testing.T) {
+	clock := NewFakeClock(fakeEpoch)
+	s := NewScheduledExecutor(WithClock(clock))
+	defer s.Shutdown()
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	var runs atomic.Int64
+	f := s.ScheduleWithFixedDelay(0, time.Second, func() error {
+		if runs.Add(1) == 1 {
+			close(running)
+			<-release
+		}
+		return nil
+	})
+	<-running
+	require.True(t, f.Cancel())
+	close(release)
+
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.queue) == 0
+	}, time.Second, time.Millisecond)
+	clock.Advance(time.Minute)
+	require.Equal(t, int64(1), runs.Load())
+}
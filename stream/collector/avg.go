@@ -15,7 +15,7 @@ type AvgContainer[R constraints.Integer | constraints.Float] struct {
 // Note: The returned Collector is not routine-safe.
 func NewAvgCollector[T any, R constraints.Integer | constraints.Float](
 	mapper func(T) R) Collector[T, *AvgContainer[R], R] {
-	return NewBaseCollector[T, *AvgContainer[R], R](
+	return NewBaseParallelCollector[T, *AvgContainer[R], R](
 		func() *AvgContainer[R] {
 			return &AvgContainer[R]{accumulator: 0, counter: 0}
 		},
@@ -23,6 +23,11 @@ func NewAvgCollector[T any, R constraints.Integer | constraints.Float](
 			container.accumulator += mapper(item)
 			container.counter++
 		},
+		func(a *AvgContainer[R], b *AvgContainer[R]) *AvgContainer[R] {
+			a.accumulator += b.accumulator
+			a.counter += b.counter
+			return a
+		},
 		func(container *AvgContainer[R]) R {
 			return container.accumulator / R(container.counter)
 		},
@@ -0,0 +1,235 @@
+package collector
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+const bloomFilterVersion = 1
+
+// BloomFilter is a set of items which may report false positives but never false negatives.
+//
+// Note: BloomFilter is not routine-safe.
+type BloomFilter[T any] struct {
+	bits  []uint64
+	m     uint64
+	k     uint32
+	count uint64
+	hash  func(T) uint64
+}
+
+// NewBloomFilter returns a Bloom filter sized for n items with the false positive rate fpRate,
+// the items are hashed by Hash.
+func NewBloomFilter[T any](n uint64, fpRate float64) *BloomFilter[T] {
+	return NewBloomFilterWithHash[T](n, fpRate, nil)
+}
+
+// NewBloomFilterWithHash is like NewBloomFilter, but the items are hashed by hashFn, or by Hash if hashFn is nil.
+func NewBloomFilterWithHash[T any](n uint64, fpRate float64, hashFn func(T) uint64) *BloomFilter[T] {
+	if fpRate <= 0 || fpRate >= 1 {
+		panic("false positive rate must be in (0, 1)")
+	}
+	if n == 0 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k == 0 {
+		k = 1
+	}
+	return &BloomFilter[T]{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+		hash: hashFn,
+	}
+}
+
+// Add adds an item
+func (bf *BloomFilter[T]) Add(item T) {
+	bf.AddHash(bf.hashOf(item))
+}
+
+// AddHash adds an item by its hash
+func (bf *BloomFilter[T]) AddHash(hash uint64) {
+	h1, h2 := bloomHashes(hash)
+	for i := uint32(0); i < bf.k; i++ {
+		bit := (h1 + uint64(i)*h2) % bf.m
+		bf.bits[bit/64] |= 1 << (bit % 64)
+	}
+	bf.count++
+}
+
+// Contains returns true if the item may have been added, false if it has not been added
+func (bf *BloomFilter[T]) Contains(item T) bool {
+	return bf.ContainsHash(bf.hashOf(item))
+}
+
+// ContainsHash is like Contains, but the item is given by its hash
+func (bf *BloomFilter[T]) ContainsHash(hash uint64) bool {
+	h1, h2 := bloomHashes(hash)
+	for i := uint32(0); i < bf.k; i++ {
+		bit := (h1 + uint64(i)*h2) % bf.m
+		if bf.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of added items, including the duplicate ones
+func (bf *BloomFilter[T]) Count() uint64 {
+	return bf.count
+}
+
+// Merge merges the other Bloom filter into bf, both must be created with the same n and fpRate
+func (bf *BloomFilter[T]) Merge(other *BloomFilter[T]) {
+	if bf.m != other.m || bf.k != other.k {
+		panic("bloom filter size mismatch")
+	}
+	for i, word := range other.bits {
+		bf.bits[i] |= word
+	}
+	bf.count += other.count
+}
+
+// MarshalBinary encodes the Bloom filter into bytes, the hash function is not encoded
+func (bf *BloomFilter[T]) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 21+8*len(bf.bits))
+	data[0] = bloomFilterVersion
+	binary.BigEndian.PutUint64(data[1:], bf.m)
+	binary.BigEndian.PutUint32(data[9:], bf.k)
+	binary.BigEndian.PutUint64(data[13:], bf.count)
+	for i, word := range bf.bits {
+		binary.BigEndian.PutUint64(data[21+8*i:], word)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes the Bloom filter from bytes, the hash function is kept
+func (bf *BloomFilter[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < 21 || data[0] != bloomFilterVersion {
+		return errors.New("bloom filter: invalid data")
+	}
+	m := binary.BigEndian.Uint64(data[1:])
+	k := binary.BigEndian.Uint32(data[9:])
+	if m == 0 || k == 0 || uint64(len(data)-21) != (m+63)/64*8 {
+		return errors.New("bloom filter: invalid data")
+	}
+
+	bf.m, bf.k = m, k
+	bf.count = binary.BigEndian.Uint64(data[13:])
+	bf.bits = make([]uint64, (m+63)/64)
+	for i := range bf.bits {
+		bf.bits[i] = binary.BigEndian.Uint64(data[21+8*i:])
+	}
+	return nil
+}
+
+func (bf *BloomFilter[T]) hashOf(item T) uint64 {
+	if bf.hash == nil {
+		return Hash(item)
+	}
+	return bf.hash(item)
+}
+
+// bloomHashes derives two independent hashes from the hash for double hashing,
+// the second one is odd so that the probes do not repeat.
+func bloomHashes(hash uint64) (h1, h2 uint64) {
+	h1 = mix64(hash)
+	h2 = mix64(h1^0x9e3779b97f4a7c15) | 1
+	return h1, h2
+}
+
+// ScalableBloomFilter is a Bloom filter which grows with the number of items,
+// while keeping the overall false positive rate below the given one.
+//
+// When the current filter is full, a new filter twice as large and with half of the false positive rate is added,
+// an item is contained if any filter contains it.
+//
+// Note: ScalableBloomFilter is not routine-safe.
+type ScalableBloomFilter[T any] struct {
+	filters  []*BloomFilter[T]
+	capacity uint64
+	fpRate   float64
+	hash     func(T) uint64
+}
+
+// NewScalableBloomFilter returns a scalable Bloom filter whose first filter is sized for initialCapacity items,
+// the items are hashed by hashFn, or by Hash if hashFn is nil.
+func NewScalableBloomFilter[T any](initialCapacity uint64, fpRate float64, hashFn func(T) uint64) *ScalableBloomFilter[T] {
+	if fpRate <= 0 || fpRate >= 1 {
+		panic("false positive rate must be in (0, 1)")
+	}
+	if initialCapacity == 0 {
+		initialCapacity = 1
+	}
+	return &ScalableBloomFilter[T]{
+		capacity: initialCapacity,
+		// the rates of the filters are fpRate/2, fpRate/4, ..., so that their sum is below fpRate
+		fpRate: fpRate / 2,
+		hash:   hashFn,
+	}
+}
+
+// Add adds an item
+func (sbf *ScalableBloomFilter[T]) Add(item T) {
+	sbf.AddHash(sbf.hashOf(item))
+}
+
+// AddHash adds an item by its hash
+func (sbf *ScalableBloomFilter[T]) AddHash(hash uint64) {
+	if len(sbf.filters) == 0 || sbf.filters[len(sbf.filters)-1].count >= sbf.capacity {
+		if len(sbf.filters) > 0 {
+			sbf.capacity *= 2
+			sbf.fpRate /= 2
+		}
+		sbf.filters = append(sbf.filters, NewBloomFilterWithHash[T](sbf.capacity, sbf.fpRate, sbf.hash))
+	}
+	sbf.filters[len(sbf.filters)-1].AddHash(hash)
+}
+
+// Contains returns true if the item may have been added, false if it has not been added
+func (sbf *ScalableBloomFilter[T]) Contains(item T) bool {
+	return sbf.ContainsHash(sbf.hashOf(item))
+}
+
+// ContainsHash is like Contains, but the item is given by its hash
+func (sbf *ScalableBloomFilter[T]) ContainsHash(hash uint64) bool {
+	for _, filter := range sbf.filters {
+		if filter.ContainsHash(hash) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sbf *ScalableBloomFilter[T]) hashOf(item T) uint64 {
+	if sbf.hash == nil {
+		return Hash(item)
+	}
+	return sbf.hash(item)
+}
+
+// NewBloomFilterCollector returns a Collector that adds the elements of the stream to a Bloom filter
+// sized for n elements with the false positive rate fpRate, see NewBloomFilter.
+//
+// Note: The returned Collector is not routine-safe.
+func NewBloomFilterCollector[T any](n uint64, fpRate float64) Collector[T, *BloomFilter[T], *BloomFilter[T]] {
+	return NewBaseParallelCollector[T, *BloomFilter[T], *BloomFilter[T]](
+		func() *BloomFilter[T] {
+			return NewBloomFilter[T](n, fpRate)
+		},
+		func(container *BloomFilter[T], item T) {
+			container.Add(item)
+		},
+		func(a *BloomFilter[T], b *BloomFilter[T]) *BloomFilter[T] {
+			a.Merge(b)
+			return a
+		},
+		func(container *BloomFilter[T]) *BloomFilter[T] {
+			return container
+		},
+	)
+}
@@ -52,3 +52,37 @@ func (bc *BaseCollector[T, A, R]) Accumulator() func(container A, item T) {
 func (bc *BaseCollector[T, A, R]) Finisher() func(container A) R {
 	return bc.finisher
 }
+
+// ParallelCollector is a Collector whose containers can be merged,
+// so that each worker of a parallel stream can accumulate into its own container without locking.
+//
+// Most collectors of this package are ParallelCollectors, so they can collect a parallel stream
+// although they are not routine-safe, see stream.Collect.
+type ParallelCollector[T any, A any, R any] interface {
+	Collector[T, A, R]
+	// Combiner returns a function that merges the second container into the first one, and returns the merged container.
+	Combiner() func(a A, b A) A
+}
+
+var _ ParallelCollector[any, any, any] = (*BaseParallelCollector[any, any, any])(nil)
+
+type BaseParallelCollector[T any, A any, R any] struct {
+	*BaseCollector[T, A, R]
+	combiner func(a A, b A) A
+}
+
+func NewBaseParallelCollector[T any, A any, R any](
+	supplier func() A,
+	accumulator func(container A, item T),
+	combiner func(a A, b A) A,
+	finisher func(container A) R,
+) *BaseParallelCollector[T, A, R] {
+	return &BaseParallelCollector[T, A, R]{
+		BaseCollector: NewBaseCollector[T, A, R](supplier, accumulator, finisher),
+		combiner:      combiner,
+	}
+}
+
+func (bc *BaseParallelCollector[T, A, R]) Combiner() func(a A, b A) A {
+	return bc.combiner
+}
@@ -16,13 +16,17 @@ type CountContainerInParallel struct {
 //
 // Note: The returned Collector is not routine-safe.
 func NewCountCollector[T any]() Collector[T, *CountContainer, int] {
-	return NewBaseCollector[T, *CountContainer, int](
+	return NewBaseParallelCollector[T, *CountContainer, int](
 		func() *CountContainer {
 			return &CountContainer{}
 		},
 		func(container *CountContainer, item T) {
 			container.counter++
 		},
+		func(a *CountContainer, b *CountContainer) *CountContainer {
+			a.counter += b.counter
+			return a
+		},
 		func(container *CountContainer) int {
 			return container.counter
 		},
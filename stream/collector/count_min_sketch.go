@@ -0,0 +1,235 @@
+package collector
+
+import (
+	"math"
+	"sort"
+)
+
+// CountMinSketch estimates the counts of items in sublinear space,
+// an estimate is never less than the true count, and exceeds it by at most epsilon * total count
+// with the probability 1 - delta.
+//
+// Note: CountMinSketch is not routine-safe.
+type CountMinSketch[T any] struct {
+	width  uint64
+	depth  uint64
+	counts []uint64
+	total  uint64
+	hash   func(T) uint64
+}
+
+// NewCountMinSketch returns a Count-Min Sketch with the error epsilon and the failure probability delta,
+// the items are hashed by hashFn, or by Hash if hashFn is nil.
+func NewCountMinSketch[T any](epsilon, delta float64, hashFn func(T) uint64) *CountMinSketch[T] {
+	if epsilon <= 0 || epsilon >= 1 {
+		panic("epsilon must be in (0, 1)")
+	}
+	if delta <= 0 || delta >= 1 {
+		panic("delta must be in (0, 1)")
+	}
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := uint64(math.Ceil(math.Log(1 / delta)))
+	return &CountMinSketch[T]{
+		width:  width,
+		depth:  depth,
+		counts: make([]uint64, width*depth),
+		hash:   hashFn,
+	}
+}
+
+// Add adds an item once
+func (s *CountMinSketch[T]) Add(item T) {
+	s.AddCount(item, 1)
+}
+
+// AddCount adds an item count times
+func (s *CountMinSketch[T]) AddCount(item T, count uint64) {
+	h1, h2 := bloomHashes(s.hashOf(item))
+	for row := uint64(0); row < s.depth; row++ {
+		s.counts[row*s.width+(h1+row*h2)%s.width] += count
+	}
+	s.total += count
+}
+
+// Estimate returns the estimated count of the item
+func (s *CountMinSketch[T]) Estimate(item T) uint64 {
+	h1, h2 := bloomHashes(s.hashOf(item))
+	estimate := uint64(math.MaxUint64)
+	for row := uint64(0); row < s.depth; row++ {
+		if count := s.counts[row*s.width+(h1+row*h2)%s.width]; count < estimate {
+			estimate = count
+		}
+	}
+	return estimate
+}
+
+// Total returns the total count of the added items
+func (s *CountMinSketch[T]) Total() uint64 {
+	return s.total
+}
+
+// Merge merges the other sketch into the sketch, both must be created with the same epsilon and delta
+func (s *CountMinSketch[T]) Merge(other *CountMinSketch[T]) {
+	if s.width != other.width || s.depth != other.depth {
+		panic("count-min sketch size mismatch")
+	}
+	for i, count := range other.counts {
+		s.counts[i] += count
+	}
+	s.total += other.total
+}
+
+func (s *CountMinSketch[T]) hashOf(item T) uint64 {
+	if s.hash == nil {
+		return Hash(item)
+	}
+	return s.hash(item)
+}
+
+// HeavyHitter is an item with its estimated count
+type HeavyHitter[K any] struct {
+	Item  K
+	Count uint64
+}
+
+// HeavyHitters tracks the k most frequent items of an unbounded stream by a CountMinSketch,
+// only the k candidates with the greatest estimated counts are kept in memory.
+//
+// Note: HeavyHitters is not routine-safe.
+type HeavyHitters[K comparable] struct {
+	k          int
+	sketch     *CountMinSketch[K]
+	candidates map[K]uint64
+	// minCount is a lower bound of the least count of the candidates,
+	// it is exact after a recomputation and only grows as the candidates are counted.
+	minCount uint64
+}
+
+// NewHeavyHitters returns a tracker of the k most frequent items,
+// epsilon and delta are the parameters of the sketch, see NewCountMinSketch.
+func NewHeavyHitters[K comparable](k int, epsilon, delta float64) *HeavyHitters[K] {
+	if k < 0 {
+		panic("k must not be negative")
+	}
+	return &HeavyHitters[K]{
+		k:          k,
+		sketch:     NewCountMinSketch[K](epsilon, delta, nil),
+		candidates: make(map[K]uint64, k),
+	}
+}
+
+// Add adds an item once
+func (h *HeavyHitters[K]) Add(item K) {
+	h.sketch.Add(item)
+	h.offer(item, h.sketch.Estimate(item))
+}
+
+// Merge merges the other tracker into the tracker, both must be created with the same parameters
+func (h *HeavyHitters[K]) Merge(other *HeavyHitters[K]) {
+	h.sketch.Merge(other.sketch)
+	for item := range other.candidates {
+		h.candidates[item] = 0
+	}
+	for item := range h.candidates {
+		h.candidates[item] = h.sketch.Estimate(item)
+	}
+	for len(h.candidates) > h.k {
+		delete(h.candidates, h.minCandidate())
+	}
+	h.minCount = 0
+}
+
+// Sketch returns the underlying sketch, which estimates the count of any item
+func (h *HeavyHitters[K]) Sketch() *CountMinSketch[K] {
+	return h.sketch
+}
+
+// Sorted returns the candidates from the most frequent to the least frequent
+func (h *HeavyHitters[K]) Sorted() []HeavyHitter[K] {
+	hitters := make([]HeavyHitter[K], 0, len(h.candidates))
+	for item, count := range h.candidates {
+		hitters = append(hitters, HeavyHitter[K]{Item: item, Count: count})
+	}
+	sort.SliceStable(hitters, func(i, j int) bool {
+		return hitters[i].Count > hitters[j].Count
+	})
+	return hitters
+}
+
+func (h *HeavyHitters[K]) offer(item K, count uint64) {
+	if _, ok := h.candidates[item]; ok || len(h.candidates) < h.k {
+		h.candidates[item] = count
+		return
+	}
+	if h.k == 0 || count <= h.minCount {
+		return
+	}
+
+	least := h.minCandidate()
+	h.minCount = h.candidates[least]
+	if count > h.minCount {
+		delete(h.candidates, least)
+		h.candidates[item] = count
+		h.minCount = h.candidates[h.minCandidate()]
+	}
+}
+
+// minCandidate returns the candidate with the least count
+func (h *HeavyHitters[K]) minCandidate() (least K) {
+	first := true
+	for item, count := range h.candidates {
+		if first || count < h.candidates[least] {
+			least, first = item, false
+		}
+	}
+	return least
+}
+
+// NewCountMinSketchCollector returns a Collector that counts the elements of the stream by a CountMinSketch,
+// see NewCountMinSketch.
+//
+// Note: The returned Collector is not routine-safe.
+func NewCountMinSketchCollector[T any](
+	epsilon, delta float64, hashFn func(T) uint64) Collector[T, *CountMinSketch[T], *CountMinSketch[T]] {
+	return NewBaseParallelCollector[T, *CountMinSketch[T], *CountMinSketch[T]](
+		func() *CountMinSketch[T] {
+			return NewCountMinSketch[T](epsilon, delta, hashFn)
+		},
+		func(container *CountMinSketch[T], item T) {
+			container.Add(item)
+		},
+		func(a *CountMinSketch[T], b *CountMinSketch[T]) *CountMinSketch[T] {
+			a.Merge(b)
+			return a
+		},
+		func(container *CountMinSketch[T]) *CountMinSketch[T] {
+			return container
+		},
+	)
+}
+
+// NewHeavyHittersCollector returns a Collector that collects the k most frequent keys of the elements of the stream
+// with their estimated counts, the result is sorted from the most frequent to the least frequent.
+//
+// The keys are counted by a CountMinSketch, so the memory does not grow with the number of distinct keys,
+// see NewHeavyHitters.
+//
+// Note: The returned Collector is not routine-safe.
+func NewHeavyHittersCollector[T any, K comparable](
+	keyMapper func(T) K, k int, epsilon, delta float64) Collector[T, *HeavyHitters[K], []HeavyHitter[K]] {
+	return NewBaseParallelCollector[T, *HeavyHitters[K], []HeavyHitter[K]](
+		func() *HeavyHitters[K] {
+			return NewHeavyHitters[K](k, epsilon, delta)
+		},
+		func(container *HeavyHitters[K], item T) {
+			container.Add(keyMapper(item))
+		},
+		func(a *HeavyHitters[K], b *HeavyHitters[K]) *HeavyHitters[K] {
+			a.Merge(b)
+			return a
+		},
+		func(container *HeavyHitters[K]) []HeavyHitter[K] {
+			return container.Sorted()
+		},
+	)
+}
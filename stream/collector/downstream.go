@@ -0,0 +1,206 @@
+package collector
+
+// TeeingContainer is the container of Teeing, which holds the containers of both collectors
+type TeeingContainer[A1 any, A2 any] struct {
+	first  A1
+	second A2
+}
+
+// GroupingBy returns a collector that groups the input elements by the key returned by keyMapper,
+// and collects the elements of each group by the downstream collector.
+//
+// If the downstream collector is a ParallelCollector, so is the returned collector.
+func GroupingBy[T any, K comparable, A any, R any](
+	keyMapper func(T) K,
+	downstream Collector[T, A, R],
+) Collector[T, map[K]A, map[K]R] {
+	supplier, accumulator, finisher := downstream.Supplier(), downstream.Accumulator(), downstream.Finisher()
+
+	var combiner func(a, b map[K]A) map[K]A
+	if downstreamCombiner, ok := combinerOf(downstream); ok {
+		combiner = func(a, b map[K]A) map[K]A {
+			for key, container := range b {
+				if existing, found := a[key]; found {
+					a[key] = downstreamCombiner(existing, container)
+				} else {
+					a[key] = container
+				}
+			}
+			return a
+		}
+	}
+
+	return newCollector[T, map[K]A, map[K]R](
+		func() map[K]A {
+			return make(map[K]A)
+		},
+		func(container map[K]A, item T) {
+			key := keyMapper(item)
+			group, found := container[key]
+			if !found {
+				group = supplier()
+				container[key] = group
+			}
+			accumulator(group, item)
+		},
+		combiner,
+		func(container map[K]A) map[K]R {
+			result := make(map[K]R, len(container))
+			for key, group := range container {
+				result[key] = finisher(group)
+			}
+			return result
+		},
+	)
+}
+
+// PartitioningBy returns a collector that partitions the input elements by the predicate,
+// and collects the elements of each partition by the downstream collector.
+//
+// The result always contains both the true and the false partitions.
+// If the downstream collector is a ParallelCollector, so is the returned collector.
+func PartitioningBy[T any, A any, R any](
+	predicate func(T) bool,
+	downstream Collector[T, A, R],
+) Collector[T, map[bool]A, map[bool]R] {
+	supplier, accumulator, finisher := downstream.Supplier(), downstream.Accumulator(), downstream.Finisher()
+
+	var combiner func(a, b map[bool]A) map[bool]A
+	if downstreamCombiner, ok := combinerOf(downstream); ok {
+		combiner = func(a, b map[bool]A) map[bool]A {
+			a[true] = downstreamCombiner(a[true], b[true])
+			a[false] = downstreamCombiner(a[false], b[false])
+			return a
+		}
+	}
+
+	return newCollector[T, map[bool]A, map[bool]R](
+		func() map[bool]A {
+			return map[bool]A{true: supplier(), false: supplier()}
+		},
+		func(container map[bool]A, item T) {
+			accumulator(container[predicate(item)], item)
+		},
+		combiner,
+		func(container map[bool]A) map[bool]R {
+			return map[bool]R{true: finisher(container[true]), false: finisher(container[false])}
+		},
+	)
+}
+
+// Teeing returns a collector that collects the input elements by both collectors,
+// and merges their results by the merger.
+//
+// If both collectors are ParallelCollectors, so is the returned collector.
+func Teeing[T any, A1 any, R1 any, A2 any, R2 any, R any](
+	first Collector[T, A1, R1],
+	second Collector[T, A2, R2],
+	merger func(first R1, second R2) R,
+) Collector[T, *TeeingContainer[A1, A2], R] {
+	firstAccumulator, secondAccumulator := first.Accumulator(), second.Accumulator()
+
+	var combiner func(a, b *TeeingContainer[A1, A2]) *TeeingContainer[A1, A2]
+	firstCombiner, firstOk := combinerOf(first)
+	secondCombiner, secondOk := combinerOf(second)
+	if firstOk && secondOk {
+		combiner = func(a, b *TeeingContainer[A1, A2]) *TeeingContainer[A1, A2] {
+			a.first = firstCombiner(a.first, b.first)
+			a.second = secondCombiner(a.second, b.second)
+			return a
+		}
+	}
+
+	return newCollector[T, *TeeingContainer[A1, A2], R](
+		func() *TeeingContainer[A1, A2] {
+			return &TeeingContainer[A1, A2]{first: first.Supplier()(), second: second.Supplier()()}
+		},
+		func(container *TeeingContainer[A1, A2], item T) {
+			firstAccumulator(container.first, item)
+			secondAccumulator(container.second, item)
+		},
+		combiner,
+		func(container *TeeingContainer[A1, A2]) R {
+			return merger(first.Finisher()(container.first), second.Finisher()(container.second))
+		},
+	)
+}
+
+// Mapping returns a collector that maps the input elements by the mapper before the downstream collector.
+//
+// If the downstream collector is a ParallelCollector, so is the returned collector.
+func Mapping[T any, U any, A any, R any](
+	mapper func(T) U,
+	downstream Collector[U, A, R],
+) Collector[T, A, R] {
+	accumulator := downstream.Accumulator()
+	combiner, _ := combinerOf(downstream)
+	return newCollector[T, A, R](
+		downstream.Supplier(),
+		func(container A, item T) {
+			accumulator(container, mapper(item))
+		},
+		combiner,
+		downstream.Finisher(),
+	)
+}
+
+// Filtering returns a collector that only passes the input elements matching the predicate to the downstream collector.
+//
+// If the downstream collector is a ParallelCollector, so is the returned collector.
+func Filtering[T any, A any, R any](
+	predicate func(T) bool,
+	downstream Collector[T, A, R],
+) Collector[T, A, R] {
+	accumulator := downstream.Accumulator()
+	combiner, _ := combinerOf(downstream)
+	return newCollector[T, A, R](
+		downstream.Supplier(),
+		func(container A, item T) {
+			if predicate(item) {
+				accumulator(container, item)
+			}
+		},
+		combiner,
+		downstream.Finisher(),
+	)
+}
+
+// CollectingAndThen returns a collector that transforms the result of the given collector by the finisher.
+//
+// If the given collector is a ParallelCollector, so is the returned collector.
+func CollectingAndThen[T any, A any, R any, RR any](
+	c Collector[T, A, R],
+	finisher func(R) RR,
+) Collector[T, A, RR] {
+	downstreamFinisher := c.Finisher()
+	combiner, _ := combinerOf(c)
+	return newCollector[T, A, RR](
+		c.Supplier(),
+		c.Accumulator(),
+		combiner,
+		func(container A) RR {
+			return finisher(downstreamFinisher(container))
+		},
+	)
+}
+
+// combinerOf returns the combiner of the collector if it is a ParallelCollector
+func combinerOf[T any, A any, R any](c Collector[T, A, R]) (combiner func(a, b A) A, ok bool) {
+	if pc, ok := c.(ParallelCollector[T, A, R]); ok {
+		return pc.Combiner(), true
+	}
+	return nil, false
+}
+
+// newCollector returns a ParallelCollector if the combiner is not nil, otherwise a Collector
+func newCollector[T any, A any, R any](
+	supplier func() A,
+	accumulator func(container A, item T),
+	combiner func(a, b A) A,
+	finisher func(container A) R,
+) Collector[T, A, R] {
+	if combiner != nil {
+		return NewBaseParallelCollector[T, A, R](supplier, accumulator, combiner, finisher)
+	}
+	return NewBaseCollector[T, A, R](supplier, accumulator, finisher)
+}
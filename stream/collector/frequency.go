@@ -0,0 +1,25 @@
+package collector
+
+// NewFrequencyCollector returns a Collector that counts the elements of the stream by their keys,
+// which are the result of applying keyMapper to the elements.
+//
+// Note: The returned Collector is not routine-safe.
+func NewFrequencyCollector[T any, K comparable](keyMapper func(T) K) Collector[T, map[K]int, map[K]int] {
+	return NewBaseParallelCollector[T, map[K]int, map[K]int](
+		func() map[K]int {
+			return make(map[K]int)
+		},
+		func(container map[K]int, item T) {
+			container[keyMapper(item)]++
+		},
+		func(a map[K]int, b map[K]int) map[K]int {
+			for key, count := range b {
+				a[key] += count
+			}
+			return a
+		},
+		func(container map[K]int) map[K]int {
+			return container
+		},
+	)
+}
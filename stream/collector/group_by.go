@@ -20,7 +20,7 @@ func NewGroupByCollectorWithValueMapper[T any, K comparable, V any](
 	keyMapper func(T) K,
 	valueMapper func(T) (v V),
 ) Collector[T, map[K][]V, map[K][]V] {
-	return NewBaseCollector[T, map[K][]V, map[K][]V](
+	return NewBaseParallelCollector[T, map[K][]V, map[K][]V](
 		func() map[K][]V {
 			return make(map[K][]V)
 		},
@@ -29,6 +29,12 @@ func NewGroupByCollectorWithValueMapper[T any, K comparable, V any](
 			value := valueMapper(item)
 			container[key] = append(container[key], value)
 		},
+		func(a map[K][]V, b map[K][]V) map[K][]V {
+			for key, values := range b {
+				a[key] = append(a[key], values...)
+			}
+			return a
+		},
 		func(container map[K][]V) map[K][]V {
 			return container
 		},
@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+)
+
+// Hash returns a 64-bit hash of the item, which is stable across processes,
+// so that the sketches built by it can be persisted and merged later.
+//
+// The type of the item is hashed with its value, so equal values of different types, such as int8(1) and "1",
+// have different hashes. Arrays and structs are hashed element by element and field by field,
+// slices and maps by their contents, and String methods are never called.
+// Pointers, channels and functions are hashed by their addresses, which are not stable across processes.
+func Hash[T any](item T) uint64 {
+	w := &hashWriter{h: fnv.New64a()}
+	switch v := any(item).(type) {
+	case string:
+		w.writeString("string")
+		w.writeString(v)
+	case []byte:
+		w.writeString("[]uint8")
+		w.writeUint64(uint64(len(v)))
+		_, _ = w.h.Write(v)
+	case int:
+		w.writeString("int")
+		w.writeUint64(uint64(v))
+	case int64:
+		w.writeString("int64")
+		w.writeUint64(uint64(v))
+	case uint64:
+		w.writeString("uint64")
+		w.writeUint64(v)
+	default:
+		// the dynamic type is hashed, so that Hash[any](item) equals to Hash(item)
+		w.writeValue(reflect.ValueOf(&item).Elem())
+	}
+	return mix64(w.h.Sum64())
+}
+
+// hashWriter writes the values into the hash, strings and sequences are prefixed by their lengths,
+// so that the concatenations of different values cannot collide.
+type hashWriter struct {
+	h   hash.Hash64
+	buf [8]byte
+}
+
+func (w *hashWriter) writeUint64(v uint64) {
+	binary.LittleEndian.PutUint64(w.buf[:], v)
+	_, _ = w.h.Write(w.buf[:])
+}
+
+func (w *hashWriter) writeString(s string) {
+	w.writeUint64(uint64(len(s)))
+	_, _ = w.h.Write([]byte(s))
+}
+
+func (w *hashWriter) writeFloat(f float64) {
+	if f == 0 {
+		// -0 equals to +0
+		f = 0
+	}
+	w.writeUint64(math.Float64bits(f))
+}
+
+// writeValue writes the type and the value, the type of an interface is its dynamic type
+func (w *hashWriter) writeValue(v reflect.Value) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			w.writeString("")
+			return
+		}
+		v = v.Elem()
+	}
+	w.writeString(v.Type().String())
+	w.writeElem(v)
+}
+
+// writeElem writes the value without its type, which is known by the enclosing array, slice or struct
+func (w *hashWriter) writeElem(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			w.writeUint64(1)
+		} else {
+			w.writeUint64(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.writeUint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.writeUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		w.writeFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		w.writeFloat(real(c))
+		w.writeFloat(imag(c))
+	case reflect.String:
+		w.writeString(v.String())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			w.writeElem(v.Index(i))
+		}
+	case reflect.Slice:
+		w.writeUint64(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			w.writeElem(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			w.writeElem(v.Field(i))
+		}
+	case reflect.Interface:
+		w.writeValue(v)
+	case reflect.Map:
+		// the entries are combined regardless of the iteration order
+		w.writeUint64(uint64(v.Len()))
+		var sum uint64
+		it := v.MapRange()
+		for it.Next() {
+			entry := &hashWriter{h: fnv.New64a()}
+			entry.writeElem(it.Key())
+			entry.writeElem(it.Value())
+			sum += mix64(entry.h.Sum64())
+		}
+		w.writeUint64(sum)
+	case reflect.Pointer, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		w.writeUint64(uint64(v.Pointer()))
+	}
+}
+
+// mix64 is the finalizer of SplitMix64, which spreads the bits of a weak hash over all 64 bits
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
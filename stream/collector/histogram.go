@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"golang.org/x/exp/constraints"
+	"math"
+	"sort"
+)
+
+// Histogram counts the values by buckets, the bucket i holds the values in (Bounds[i-1], Bounds[i]],
+// and the last bucket holds the values greater than the last bound.
+type Histogram struct {
+	// Bounds are the ascending upper bounds of the buckets
+	Bounds []float64
+	// Counts are the number of values in each bucket, there is one more count than bounds
+	Counts []int64
+	// Count is the number of values
+	Count int64
+	// Sum is the sum of values
+	Sum float64
+}
+
+// NewHistogram returns an empty histogram with the given ascending upper bounds
+func NewHistogram(bounds []float64) *Histogram {
+	if !sort.Float64sAreSorted(bounds) {
+		panic("bounds must be sorted in ascending order")
+	}
+	return &Histogram{
+		Bounds: append([]float64(nil), bounds...),
+		Counts: make([]int64, len(bounds)+1),
+	}
+}
+
+// Add adds a value to the histogram
+func (h *Histogram) Add(value float64) {
+	h.Counts[sort.SearchFloat64s(h.Bounds, value)]++
+	h.Count++
+	h.Sum += value
+}
+
+// Merge merges the other histogram into the histogram, both must have the same bounds
+func (h *Histogram) Merge(other *Histogram) {
+	if len(h.Bounds) != len(other.Bounds) {
+		panic("histogram bounds mismatch")
+	}
+	for i, bound := range other.Bounds {
+		if h.Bounds[i] != bound {
+			panic("histogram bounds mismatch")
+		}
+	}
+	for i, count := range other.Counts {
+		h.Counts[i] += count
+	}
+	h.Count += other.Count
+	h.Sum += other.Sum
+}
+
+// LinearBuckets returns count upper bounds, the first one is start and each one is width greater than the previous one
+func LinearBuckets(start, width float64, count int) []float64 {
+	if width <= 0 {
+		panic("width must be positive")
+	}
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start + float64(i)*width
+	}
+	return bounds
+}
+
+// ExponentialBuckets returns count upper bounds, the first one is start and each one is factor times the previous one
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	if start <= 0 {
+		panic("start must be positive")
+	}
+	if factor <= 1 {
+		panic("factor must be greater than 1")
+	}
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start * math.Pow(factor, float64(i))
+	}
+	return bounds
+}
+
+// NewHistogramCollector returns a Collector that counts the elements of the stream by buckets
+// with the given ascending upper bounds, see LinearBuckets and ExponentialBuckets.
+//
+// Note: The returned Collector is not routine-safe.
+func NewHistogramCollector[T any, R constraints.Integer | constraints.Float](
+	mapper func(T) R, bounds []float64) Collector[T, *Histogram, *Histogram] {
+	if !sort.Float64sAreSorted(bounds) {
+		panic("bounds must be sorted in ascending order")
+	}
+	return NewBaseParallelCollector[T, *Histogram, *Histogram](
+		func() *Histogram {
+			return NewHistogram(bounds)
+		},
+		func(container *Histogram, item T) {
+			container.Add(float64(mapper(item)))
+		},
+		func(a *Histogram, b *Histogram) *Histogram {
+			a.Merge(b)
+			return a
+		},
+		func(container *Histogram) *Histogram {
+			return container
+		},
+	)
+}
@@ -14,7 +14,7 @@ type MaxContainer[R constraints.Ordered] struct {
 // Note: The returned Collector is not routine-safe.
 func NewMaxCollector[T any, R constraints.Ordered](
 	mapper func(T) R) Collector[T, *MaxContainer[R], R] {
-	return NewBaseCollector[T, *MaxContainer[R], R](
+	return NewBaseParallelCollector[T, *MaxContainer[R], R](
 		func() *MaxContainer[R] {
 			return &MaxContainer[R]{}
 		},
@@ -24,6 +24,12 @@ func NewMaxCollector[T any, R constraints.Ordered](
 				container.max = &val
 			}
 		},
+		func(a *MaxContainer[R], b *MaxContainer[R]) *MaxContainer[R] {
+			if a.max == nil || b.max != nil && *b.max > *a.max {
+				a.max = b.max
+			}
+			return a
+		},
 		func(container *MaxContainer[R]) R {
 			return *container.max
 		},
@@ -14,7 +14,7 @@ type MinContainer[R constraints.Ordered] struct {
 // Note: The returned Collector is not routine-safe.
 func NewMinCollector[T any, R constraints.Ordered](
 	mapper func(T) R) Collector[T, *MinContainer[R], R] {
-	return NewBaseCollector[T, *MinContainer[R], R](
+	return NewBaseParallelCollector[T, *MinContainer[R], R](
 		func() *MinContainer[R] {
 			return &MinContainer[R]{}
 		},
@@ -24,6 +24,12 @@ func NewMinCollector[T any, R constraints.Ordered](
 				container.min = &val
 			}
 		},
+		func(a *MinContainer[R], b *MinContainer[R]) *MinContainer[R] {
+			if a.min == nil || b.min != nil && *b.min < *a.min {
+				a.min = b.min
+			}
+			return a
+		},
 		func(container *MinContainer[R]) R {
 			return *container.min
 		},
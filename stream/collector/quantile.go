@@ -0,0 +1,195 @@
+package collector
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DefaultQuantileSketchK is the default accuracy parameter of QuantileSketch,
+// whose rank error is about 1%.
+const DefaultQuantileSketchK = 200
+
+// QuantileSketch is a KLL sketch, which estimates the quantiles of a stream of values in sublinear space.
+//
+// The values are kept in a hierarchy of compactors, the values of level h have the weight 2^h.
+// When a compactor is full, it is sorted and every other value is promoted to the next level.
+// Two sketches can be merged, so the sketch of a parallel stream can be built by parts.
+//
+// Note: QuantileSketch is not routine-safe.
+type QuantileSketch struct {
+	k          int
+	compactors [][]float64
+	size       int
+	maxSize    int
+	count      int64
+	min        float64
+	max        float64
+}
+
+// NewQuantileSketch returns a sketch with the accuracy parameter k,
+// the larger k is, the more accurate and the larger the sketch is.
+// If k is not positive, DefaultQuantileSketchK is used.
+func NewQuantileSketch(k int) *QuantileSketch {
+	if k <= 0 {
+		k = DefaultQuantileSketchK
+	}
+	s := &QuantileSketch{k: k}
+	s.grow()
+	return s
+}
+
+// Add adds a value to the sketch
+func (s *QuantileSketch) Add(value float64) {
+	if s.count == 0 || value < s.min {
+		s.min = value
+	}
+	if s.count == 0 || value > s.max {
+		s.max = value
+	}
+	s.count++
+
+	s.compactors[0] = append(s.compactors[0], value)
+	s.size++
+	if s.size >= s.maxSize {
+		s.compress()
+	}
+}
+
+// Merge merges the other sketch into the sketch
+func (s *QuantileSketch) Merge(other *QuantileSketch) {
+	if other.count == 0 {
+		return
+	}
+	if s.count == 0 || other.min < s.min {
+		s.min = other.min
+	}
+	if s.count == 0 || other.max > s.max {
+		s.max = other.max
+	}
+	s.count += other.count
+
+	for len(s.compactors) < len(other.compactors) {
+		s.grow()
+	}
+	for h, compactor := range other.compactors {
+		s.compactors[h] = append(s.compactors[h], compactor...)
+	}
+	s.size += other.size
+	for s.size >= s.maxSize {
+		s.compress()
+	}
+}
+
+// Count returns the number of values added to the sketch
+func (s *QuantileSketch) Count() int64 {
+	return s.count
+}
+
+// Quantile returns the approximate value at the given quantile, q must be in [0, 1].
+// It returns NaN if the sketch is empty.
+func (s *QuantileSketch) Quantile(q float64) float64 {
+	return s.Quantiles(q)[0]
+}
+
+// Quantiles returns the approximate values at the given quantiles, each quantile must be in [0, 1].
+// It returns NaNs if the sketch is empty.
+func (s *QuantileSketch) Quantiles(qs ...float64) []float64 {
+	for _, q := range qs {
+		if q < 0 || q > 1 {
+			panic("quantile must be in [0, 1]")
+		}
+	}
+
+	values := make([]float64, len(qs))
+	if s.count == 0 {
+		for i := range values {
+			values[i] = math.NaN()
+		}
+		return values
+	}
+
+	type weighted struct {
+		value  float64
+		weight int64
+	}
+	items := make([]weighted, 0, s.size)
+	var total int64
+	for h, compactor := range s.compactors {
+		weight := int64(1) << h
+		for _, value := range compactor {
+			items = append(items, weighted{value: value, weight: weight})
+			total += weight
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].value < items[j].value
+	})
+
+	for i, q := range qs {
+		switch q {
+		case 0:
+			values[i] = s.min
+		case 1:
+			values[i] = s.max
+		default:
+			rank := int64(math.Ceil(q * float64(total)))
+			var cumulative int64
+			values[i] = s.max
+			for _, item := range items {
+				cumulative += item.weight
+				if cumulative >= rank {
+					values[i] = item.value
+					break
+				}
+			}
+		}
+	}
+	return values
+}
+
+// grow adds a new level on the top of the compactors
+func (s *QuantileSketch) grow() {
+	s.compactors = append(s.compactors, nil)
+	s.maxSize = 0
+	for h := range s.compactors {
+		s.maxSize += s.capacity(h)
+	}
+}
+
+// capacity returns the capacity of the compactor at the given level,
+// the capacities decrease geometrically from the top level downwards.
+func (s *QuantileSketch) capacity(h int) int {
+	depth := len(s.compactors) - h - 1
+	return int(math.Ceil(math.Pow(2.0/3.0, float64(depth))*float64(s.k))) + 1
+}
+
+// compress compacts the full compactors from the bottom level upwards until the sketch is not full
+func (s *QuantileSketch) compress() {
+	for h := 0; h < len(s.compactors); h++ {
+		if len(s.compactors[h]) < s.capacity(h) {
+			continue
+		}
+		if h+1 >= len(s.compactors) {
+			s.grow()
+		}
+
+		compactor := s.compactors[h]
+		sort.Float64s(compactor)
+		// keep the last value if the compactor has an odd number of values
+		var kept []float64
+		if len(compactor)%2 == 1 {
+			kept = append(kept, compactor[len(compactor)-1])
+			compactor = compactor[:len(compactor)-1]
+		}
+		for i := rand.Intn(2); i < len(compactor); i += 2 {
+			s.compactors[h+1] = append(s.compactors[h+1], compactor[i])
+		}
+		s.size -= len(compactor) / 2
+		s.compactors[h] = append(s.compactors[h][:0], kept...)
+
+		if s.size < s.maxSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"golang.org/x/exp/constraints"
+	"math"
+)
+
+// SummaryStatistics holds the count, sum, min, max, mean and variance of the elements,
+// which are computed in one pass by Welford's algorithm.
+type SummaryStatistics struct {
+	Count int64
+	Sum   float64
+	Min   float64
+	Max   float64
+	Mean  float64
+	// m2 is the sum of squares of differences from the mean
+	m2 float64
+}
+
+// Add adds a value to the statistics
+func (s *SummaryStatistics) Add(value float64) {
+	s.Count++
+	s.Sum += value
+	if s.Count == 1 || value < s.Min {
+		s.Min = value
+	}
+	if s.Count == 1 || value > s.Max {
+		s.Max = value
+	}
+
+	delta := value - s.Mean
+	s.Mean += delta / float64(s.Count)
+	s.m2 += delta * (value - s.Mean)
+}
+
+// Merge merges the other statistics into the statistics
+func (s *SummaryStatistics) Merge(other *SummaryStatistics) {
+	if other.Count == 0 {
+		return
+	}
+	if s.Count == 0 {
+		*s = *other
+		return
+	}
+
+	count := s.Count + other.Count
+	delta := other.Mean - s.Mean
+	s.Mean += delta * float64(other.Count) / float64(count)
+	s.m2 += other.m2 + delta*delta*float64(s.Count)*float64(other.Count)/float64(count)
+	s.Count = count
+	s.Sum += other.Sum
+	s.Min = math.Min(s.Min, other.Min)
+	s.Max = math.Max(s.Max, other.Max)
+}
+
+// Variance returns the population variance, or 0 if there are no elements
+func (s SummaryStatistics) Variance() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.m2 / float64(s.Count)
+}
+
+// SampleVariance returns the sample variance, or 0 if there are less than 2 elements
+func (s SummaryStatistics) SampleVariance() float64 {
+	if s.Count < 2 {
+		return 0
+	}
+	return s.m2 / float64(s.Count-1)
+}
+
+// StdDev returns the population standard deviation
+func (s SummaryStatistics) StdDev() float64 {
+	return math.Sqrt(s.Variance())
+}
+
+// NewSummaryStatisticsCollector returns a Collector that computes
+// the count, sum, min, max, mean and variance of the elements of the stream in one pass.
+//
+// Note: The returned Collector is not routine-safe.
+func NewSummaryStatisticsCollector[T any, R constraints.Integer | constraints.Float](
+	mapper func(T) R) Collector[T, *SummaryStatistics, SummaryStatistics] {
+	return NewBaseParallelCollector[T, *SummaryStatistics, SummaryStatistics](
+		func() *SummaryStatistics {
+			return &SummaryStatistics{}
+		},
+		func(container *SummaryStatistics, item T) {
+			container.Add(float64(mapper(item)))
+		},
+		func(a *SummaryStatistics, b *SummaryStatistics) *SummaryStatistics {
+			a.Merge(b)
+			return a
+		},
+		func(container *SummaryStatistics) SummaryStatistics {
+			return *container
+		},
+	)
+}
+
+// NewQuantileCollector returns a Collector that summarizes the elements of the stream into a QuantileSketch,
+// so that the approximate quantiles, such as p50, p95 and p99, can be queried without sorting the elements.
+//
+// k is the accuracy parameter of the sketch, see NewQuantileSketch.
+//
+// Note: The returned Collector is not routine-safe.
+func NewQuantileCollector[T any, R constraints.Integer | constraints.Float](
+	mapper func(T) R, k int) Collector[T, *QuantileSketch, *QuantileSketch] {
+	return NewBaseParallelCollector[T, *QuantileSketch, *QuantileSketch](
+		func() *QuantileSketch {
+			return NewQuantileSketch(k)
+		},
+		func(container *QuantileSketch, item T) {
+			container.Add(float64(mapper(item)))
+		},
+		func(a *QuantileSketch, b *QuantileSketch) *QuantileSketch {
+			a.Merge(b)
+			return a
+		},
+		func(container *QuantileSketch) *QuantileSketch {
+			return container
+		},
+	)
+}
@@ -14,13 +14,17 @@ type SumContainer[R constraints.Integer | constraints.Float] struct {
 // Note: The returned Collector is not routine-safe.
 func NewSumCollector[T any, R constraints.Integer | constraints.Float](
 	mapper func(T) R) Collector[T, *SumContainer[R], R] {
-	return NewBaseCollector[T, *SumContainer[R], R](
+	return NewBaseParallelCollector[T, *SumContainer[R], R](
 		func() *SumContainer[R] {
 			return &SumContainer[R]{accumulator: 0}
 		},
 		func(container *SumContainer[R], item T) {
 			container.accumulator += mapper(item)
 		},
+		func(a *SumContainer[R], b *SumContainer[R]) *SumContainer[R] {
+			a.accumulator += b.accumulator
+			return a
+		},
 		func(container *SumContainer[R]) R {
 			return container.accumulator
 		},
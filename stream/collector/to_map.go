@@ -71,18 +71,25 @@ func NewToMapCollectorWithDuplicateHandler[T any, K comparable, V any](
 	valueMapper func(T) V,
 	duplicateHandler func(duplicateKey K, existingValue V, newValue V) V,
 ) Collector[T, map[K]V, map[K]V] {
-	return NewBaseCollector[T, map[K]V, map[K]V](
+	put := func(container map[K]V, key K, newValue V) {
+		if existingValue, ok := container[key]; ok && duplicateHandler != nil {
+			container[key] = duplicateHandler(key, existingValue, newValue)
+		} else {
+			container[key] = newValue
+		}
+	}
+	return NewBaseParallelCollector[T, map[K]V, map[K]V](
 		func() map[K]V {
 			return make(map[K]V, size)
 		},
 		func(container map[K]V, item T) {
-			key := keyMapper(item)
-			newValue := valueMapper(item)
-			if existingValue, ok := container[key]; ok && duplicateHandler != nil {
-				container[key] = duplicateHandler(key, existingValue, newValue)
-			} else {
-				container[key] = newValue
+			put(container, keyMapper(item), valueMapper(item))
+		},
+		func(a map[K]V, b map[K]V) map[K]V {
+			for key, value := range b {
+				put(a, key, value)
 			}
+			return a
 		},
 		func(container map[K]V) map[K]V {
 			return container
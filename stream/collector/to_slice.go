@@ -6,8 +6,13 @@ type SliceContainer[T any] struct {
 	items []T
 }
 
+// NewToSliceCollector returns a collector that accumulates the input elements into a slice.
+//
+// size is the expected size of the slice.
+//
+// Note: The returned Collector is not routine-safe.
 func NewToSliceCollector[T any](size int) Collector[T, *SliceContainer[T], []T] {
-	return NewBaseCollector[T, *SliceContainer[T], []T](
+	return NewBaseParallelCollector[T, *SliceContainer[T], []T](
 		func() *SliceContainer[T] {
 			return &SliceContainer[T]{
 				items: make([]T, 0, size),
@@ -16,6 +21,10 @@ func NewToSliceCollector[T any](size int) Collector[T, *SliceContainer[T], []T]
 		func(container *SliceContainer[T], item T) {
 			container.items = append(container.items, item)
 		},
+		func(a *SliceContainer[T], b *SliceContainer[T]) *SliceContainer[T] {
+			a.items = append(a.items, b.items...)
+			return a
+		},
 		func(container *SliceContainer[T]) []T {
 			return container.items
 		},
@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"container/heap"
+	"golang.org/x/exp/constraints"
+	"sort"
+)
+
+// TopKContainer keeps the k greatest items in a bounded min-heap,
+// whose root is the least of them and is replaced by a greater item.
+type TopKContainer[T any] struct {
+	k    int
+	heap *topKHeap[T]
+}
+
+// NewTopKContainer returns a container that keeps the k greatest items by less
+func NewTopKContainer[T any](k int, less func(a, b T) bool) *TopKContainer[T] {
+	if k < 0 {
+		panic("k must not be negative")
+	}
+	return &TopKContainer[T]{k: k, heap: &topKHeap[T]{less: less, items: make([]T, 0, k)}}
+}
+
+// Add adds an item, it is dropped if there are k items greater than or equal to it
+func (c *TopKContainer[T]) Add(item T) {
+	h := c.heap
+	if len(h.items) < c.k {
+		heap.Push(h, item)
+	} else if c.k > 0 && h.less(h.items[0], item) {
+		h.items[0] = item
+		heap.Fix(h, 0)
+	}
+}
+
+// Merge adds the items of the other container
+func (c *TopKContainer[T]) Merge(other *TopKContainer[T]) {
+	for _, item := range other.heap.items {
+		c.Add(item)
+	}
+}
+
+// Sorted returns the kept items from the greatest to the least
+func (c *TopKContainer[T]) Sorted() []T {
+	sorted := make([]T, len(c.heap.items))
+	copy(sorted, c.heap.items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return c.heap.less(sorted[j], sorted[i])
+	})
+	return sorted
+}
+
+// topKHeap is a min-heap of items ordered by less
+type topKHeap[T any] struct {
+	less  func(a, b T) bool
+	items []T
+}
+
+func (h *topKHeap[T]) Len() int {
+	return len(h.items)
+}
+
+func (h *topKHeap[T]) Less(i, j int) bool {
+	return h.less(h.items[i], h.items[j])
+}
+
+func (h *topKHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *topKHeap[T]) Push(x any) {
+	h.items = append(h.items, x.(T))
+}
+
+func (h *topKHeap[T]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// NewTopKCollector returns a Collector that collects the k greatest elements of the stream by less,
+// the result is sorted from the greatest to the least.
+//
+// Only k elements are kept in memory, so it is cheaper than sorting the stream and limiting it to k.
+//
+// Note: The returned Collector is not routine-safe.
+func NewTopKCollector[T any](k int, less func(a, b T) bool) Collector[T, *TopKContainer[T], []T] {
+	return NewBaseParallelCollector[T, *TopKContainer[T], []T](
+		func() *TopKContainer[T] {
+			return NewTopKContainer[T](k, less)
+		},
+		func(container *TopKContainer[T], item T) {
+			container.Add(item)
+		},
+		func(a *TopKContainer[T], b *TopKContainer[T]) *TopKContainer[T] {
+			a.Merge(b)
+			return a
+		},
+		func(container *TopKContainer[T]) []T {
+			return container.Sorted()
+		},
+	)
+}
+
+// NewBottomKCollector returns a Collector that collects the k least elements of the stream by less,
+// the result is sorted from the least to the greatest.
+//
+// Note: The returned Collector is not routine-safe.
+func NewBottomKCollector[T any](k int, less func(a, b T) bool) Collector[T, *TopKContainer[T], []T] {
+	return NewTopKCollector[T](k, func(a, b T) bool {
+		return less(b, a)
+	})
+}
+
+// TopKBy returns a Collector that collects the k elements of the stream with the greatest keys,
+// see NewTopKCollector.
+func TopKBy[T any, K constraints.Ordered](k int, keyMapper func(T) K) Collector[T, *TopKContainer[T], []T] {
+	return NewTopKCollector[T](k, func(a, b T) bool {
+		return keyMapper(a) < keyMapper(b)
+	})
+}
+
+// BottomKBy returns a Collector that collects the k elements of the stream with the least keys,
+// see NewBottomKCollector.
+func BottomKBy[T any, K constraints.Ordered](k int, keyMapper func(T) K) Collector[T, *TopKContainer[T], []T] {
+	return NewBottomKCollector[T](k, func(a, b T) bool {
+		return keyMapper(a) < keyMapper(b)
+	})
+}
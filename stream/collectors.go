@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/carter-ya/go-tools/stream/collector"
 	"golang.org/x/exp/constraints"
-	"strings"
 )
 
 // NewToMapCollector returns a collector that accumulates
@@ -84,17 +83,7 @@ func NewToMapCollectorWithDuplicateHandler[T any, K comparable, V any](
 	c := collector.NewToMapCollectorWithDuplicateHandler[T, K, V](
 		size, keyMapper, valueMapper, duplicateHandler,
 	)
-
-	supplier = func() any {
-		return c.Supplier()()
-	}
-	accumulator = func(container, item any) {
-		c.Accumulator()(container.(map[K]V), item.(T))
-	}
-	finisher = func(container any) any {
-		return c.Finisher()(container.(map[K]V))
-	}
-	return supplier, accumulator, finisher
+	return adaptCollector[T](c)
 }
 
 func NewToSliceCollector[T any](
@@ -105,17 +94,7 @@ func NewToSliceCollector[T any](
 	finisher func(container any) any,
 ) {
 	c := collector.NewToSliceCollector[T](size)
-
-	supplier = func() any {
-		return c.Supplier()()
-	}
-	accumulator = func(container, item any) {
-		c.Accumulator()(container.(*collector.SliceContainer[T]), item.(T))
-	}
-	finisher = func(container any) any {
-		return c.Finisher()(container.(*collector.SliceContainer[T]))
-	}
-	return supplier, accumulator, finisher
+	return adaptCollector[T](c)
 }
 
 func NewJoiningCollector[T any](separator string) (
@@ -124,17 +103,7 @@ func NewJoiningCollector[T any](separator string) (
 	finisher func(container any) any,
 ) {
 	c := collector.NewJoiningCollector[T](separator)
-
-	supplier = func() any {
-		return c.Supplier()()
-	}
-	accumulator = func(container, item any) {
-		c.Accumulator()(container.(*strings.Builder), item.(T))
-	}
-	finisher = func(container any) any {
-		return c.Finisher()(container.(*strings.Builder))
-	}
-	return supplier, accumulator, finisher
+	return adaptCollector[T](c)
 }
 
 func NewGroupByCollector[T any, K comparable](
@@ -145,17 +114,7 @@ func NewGroupByCollector[T any, K comparable](
 	finisher func(container any) any,
 ) {
 	c := collector.NewGroupByCollector[T, K](keyMapper)
-
-	supplier = func() any {
-		return c.Supplier()()
-	}
-	accumulator = func(container, item any) {
-		c.Accumulator()(container.(map[K][]T), item.(T))
-	}
-	finisher = func(container any) any {
-		return c.Finisher()(container.(map[K][]T))
-	}
-	return supplier, accumulator, finisher
+	return adaptCollector[T](c)
 }
 
 func NewCountCollector[T any]() (
@@ -164,17 +123,7 @@ func NewCountCollector[T any]() (
 	finisher func(container any) any,
 ) {
 	c := collector.NewCountCollector[T]()
-
-	supplier = func() any {
-		return c.Supplier()()
-	}
-	accumulator = func(container, item any) {
-		c.Accumulator()(container.(*collector.CountContainer), item.(T))
-	}
-	finisher = func(container any) any {
-		return c.Finisher()(container.(*collector.CountContainer))
-	}
-	return supplier, accumulator, finisher
+	return adaptCollector[T](c)
 }
 
 func NewSumCollector[T any, R constraints.Integer | constraints.Float](
@@ -184,17 +133,7 @@ func NewSumCollector[T any, R constraints.Integer | constraints.Float](
 	finisher func(container any) any,
 ) {
 	c := collector.NewSumCollector[T, R](mapper)
-
-	supplier = func() any {
-		return c.Supplier()()
-	}
-	accumulator = func(container, item any) {
-		c.Accumulator()(container.(*collector.SumContainer[R]), item.(T))
-	}
-	finisher = func(container any) any {
-		return c.Finisher()(container.(*collector.SumContainer[R]))
-	}
-	return supplier, accumulator, finisher
+	return adaptCollector[T](c)
 }
 
 func NewAvgCollector[T any, R constraints.Integer | constraints.Float](
@@ -204,17 +143,7 @@ func NewAvgCollector[T any, R constraints.Integer | constraints.Float](
 	finisher func(container any) any,
 ) {
 	c := collector.NewAvgCollector[T, R](mapper)
-
-	supplier = func() any {
-		return c.Supplier()()
-	}
-	accumulator = func(container, item any) {
-		c.Accumulator()(container.(*collector.AvgContainer[R]), item.(T))
-	}
-	finisher = func(container any) any {
-		return c.Finisher()(container.(*collector.AvgContainer[R]))
-	}
-	return supplier, accumulator, finisher
+	return adaptCollector[T](c)
 }
 
 func NewMaxCollector[T any, R constraints.Ordered](
@@ -224,17 +153,7 @@ func NewMaxCollector[T any, R constraints.Ordered](
 	finisher func(container any) any,
 ) {
 	c := collector.NewMaxCollector[T, R](mapper)
-
-	supplier = func() any {
-		return c.Supplier()()
-	}
-	accumulator = func(container, item any) {
-		c.Accumulator()(container.(*collector.MaxContainer[R]), item.(T))
-	}
-	finisher = func(container any) any {
-		return c.Finisher()(container.(*collector.MaxContainer[R]))
-	}
-	return supplier, accumulator, finisher
+	return adaptCollector[T](c)
 }
 
 func NewMinCollector[T any, R constraints.Ordered](
@@ -244,15 +163,57 @@ func NewMinCollector[T any, R constraints.Ordered](
 	finisher func(container any) any,
 ) {
 	c := collector.NewMinCollector[T, R](mapper)
+	return adaptCollector[T](c)
+}
 
+// adaptCollector adapts the collector to the parameters of Stream.Collect.
+// The containers of a collector.ParallelCollector are wrapped by parallelContainer,
+// so that each worker of a parallel stream accumulates into its own container.
+func adaptCollector[T any, A any, R any](c collector.Collector[T, A, R]) (
+	supplier func() any,
+	accumulator func(container, item any),
+	finisher func(container any) any,
+) {
+	supply, accumulate, finish := c.Supplier(), c.Accumulator(), c.Finisher()
+	pc, ok := c.(collector.ParallelCollector[T, A, R])
+	if !ok {
+		supplier = func() any {
+			return supply()
+		}
+		accumulator = func(container, item any) {
+			accumulate(container.(A), cast[T](item))
+		}
+		finisher = func(container any) any {
+			return finish(container.(A))
+		}
+		return supplier, accumulator, finisher
+	}
+
+	combiner := pc.Combiner()
 	supplier = func() any {
-		return c.Supplier()()
+		return &parallelContainer[A]{container: supply(), combiner: combiner}
 	}
 	accumulator = func(container, item any) {
-		c.Accumulator()(container.(*collector.MinContainer[R]), item.(T))
+		accumulate(container.(*parallelContainer[A]).container, cast[T](item))
 	}
 	finisher = func(container any) any {
-		return c.Finisher()(container.(*collector.MinContainer[R]))
+		return finish(container.(*parallelContainer[A]).container)
 	}
 	return supplier, accumulator, finisher
 }
+
+// combinable is a container which can be merged with another container of the same supplier
+type combinable interface {
+	combine(other any) any
+}
+
+// parallelContainer is a container of a collector.ParallelCollector
+type parallelContainer[A any] struct {
+	container A
+	combiner  func(a A, b A) A
+}
+
+func (pc *parallelContainer[A]) combine(other any) any {
+	pc.container = pc.combiner(pc.container, other.(*parallelContainer[A]).container)
+	return pc
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/carter-ya/go-tools/stream/collector"
 	"github.com/stretchr/testify/require"
+	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -80,6 +82,49 @@ func TestCollector_Min(t *testing.T) {
 	))
 }
 
+func TestCollector_Parallel(t *testing.T) {
+	items := make([]int64, 10000)
+	for i := range items {
+		items[i] = int64(i)
+	}
+	collect := func(
+		supplier func() any,
+		accumulator func(container, item any),
+		finisher func(container any) any,
+	) (sequential, parallel any) {
+		sequential = Just[int64](items).Collect(supplier, accumulator, finisher)
+		parallel = Just[int64](items, WithParallelism(8)).Collect(supplier, accumulator, finisher)
+		return sequential, parallel
+	}
+	mapper := collector.Identify[int64]()
+
+	sequential, parallel := collect(NewSumCollector[int64](mapper))
+	require.Equal(t, sequential, parallel)
+	sequential, parallel = collect(NewAvgCollector[int64, float64](func(item int64) float64 {
+		return float64(item)
+	}))
+	require.Equal(t, sequential, parallel)
+	sequential, parallel = collect(NewCountCollector[int64]())
+	require.Equal(t, sequential, parallel)
+	sequential, parallel = collect(NewMinCollector[int64](mapper))
+	require.Equal(t, sequential, parallel)
+	sequential, parallel = collect(NewMaxCollector[int64](mapper))
+	require.Equal(t, sequential, parallel)
+	sequential, parallel = collect(NewToSliceCollector[int64](0))
+	require.ElementsMatch(t, sequential, parallel)
+	sequential, parallel = collect(NewToMapCollector[int64](0, mapper))
+	require.Equal(t, sequential, parallel)
+
+	sequential, parallel = collect(NewGroupByCollector(func(i int64) int64 {
+		return i % 7
+	}))
+	sequentialGroups, parallelGroups := sequential.(map[int64][]int64), parallel.(map[int64][]int64)
+	require.Len(t, parallelGroups, len(sequentialGroups))
+	for key, group := range sequentialGroups {
+		require.ElementsMatch(t, group, parallelGroups[key])
+	}
+}
+
 var _ fmt.Stringer = (*s)(nil)
 
 type s struct {
@@ -90,3 +135,279 @@ type s struct {
 func (s s) String() string {
 	return fmt.Sprintf("%s:%d", s.S, s.I)
 }
+
+func TestCollector_GroupingBy(t *testing.T) {
+	words := []string{"apple", "avocado", "banana", "blueberry", "cherry"}
+	firstLetter := func(word string) byte {
+		return word[0]
+	}
+
+	require.Equal(t, map[byte]int{'a': 2, 'b': 2, 'c': 1}, Collect(Of(words),
+		collector.GroupingBy(firstLetter, collector.NewCountCollector[string]()),
+	))
+	require.Equal(t, map[byte]int{'a': 12, 'b': 15, 'c': 6}, Collect(Of(words, WithParallelism(4)),
+		collector.GroupingBy(firstLetter, collector.NewSumCollector(func(word string) int {
+			return len(word)
+		})),
+	))
+	require.Equal(t, map[byte][]int{'a': {5, 7}, 'b': {6, 9}, 'c': {6}}, Collect(Of(words),
+		collector.GroupingBy(firstLetter, collector.Mapping(func(word string) int {
+			return len(word)
+		}, collector.NewToSliceCollector[int](0))),
+	))
+}
+
+func TestCollector_PartitioningBy(t *testing.T) {
+	isOdd := func(i int64) bool {
+		return i%2 == 1
+	}
+
+	require.Equal(t, map[bool]int64{true: 4, false: 6}, Collect(Of([]int64{1, 2, 3, 4}),
+		collector.PartitioningBy(isOdd, collector.NewSumCollector(collector.Identify[int64]())),
+	))
+	require.Equal(t, map[bool]int{true: 2, false: 0}, Collect(Of([]int64{1, 3}, WithParallelism(2)),
+		collector.PartitioningBy(isOdd, collector.NewCountCollector[int64]()),
+	))
+}
+
+func TestCollector_Teeing(t *testing.T) {
+	rangeOf := collector.Teeing(
+		collector.NewMinCollector(collector.Identify[int64]()),
+		collector.NewMaxCollector(collector.Identify[int64]()),
+		func(min, max int64) int64 {
+			return max - min
+		},
+	)
+	require.Equal(t, int64(8), Collect(Of([]int64{5, 1, 9, 3}), rangeOf))
+	require.Equal(t, int64(999), Collect(OfStream[int64](Range[int64](0, 1000), WithParallelism(4)), rangeOf))
+}
+
+func TestCollector_FilteringAndCollectingAndThen(t *testing.T) {
+	c := collector.CollectingAndThen(
+		collector.Filtering(func(i int64) bool {
+			return i > 2
+		}, collector.NewToSliceCollector[int64](0)),
+		func(items []int64) string {
+			return fmt.Sprint(items)
+		},
+	)
+	require.Equal(t, "[3 4]", Collect(Of([]int64{1, 2, 3, 4}), c))
+
+	_, ok := c.(collector.ParallelCollector[int64, *collector.SliceContainer[int64], string])
+	require.True(t, ok)
+}
+
+func TestCollector_SummaryStatistics(t *testing.T) {
+	for _, parallelism := range []uint{1, 4} {
+		stats := Collect(OfStream[int64](Range[int64](1, 101), WithParallelism(parallelism)),
+			collector.NewSummaryStatisticsCollector(collector.Identify[int64]()),
+		)
+		require.Equal(t, int64(100), stats.Count)
+		require.Equal(t, float64(5050), stats.Sum)
+		require.Equal(t, float64(1), stats.Min)
+		require.Equal(t, float64(100), stats.Max)
+		require.InDelta(t, 50.5, stats.Mean, 1e-9)
+		require.InDelta(t, 833.25, stats.Variance(), 1e-9)
+		require.InDelta(t, 841.6666666, stats.SampleVariance(), 1e-6)
+		require.InDelta(t, 28.8660700, stats.StdDev(), 1e-6)
+	}
+
+	empty := Collect(Of([]int{}), collector.NewSummaryStatisticsCollector(collector.Identify[int]()))
+	require.Equal(t, int64(0), empty.Count)
+	require.Equal(t, float64(0), empty.Variance())
+}
+
+func TestCollector_Quantile(t *testing.T) {
+	const n = 100000
+	for _, parallelism := range []uint{1, 4} {
+		sketch := Collect(OfStream[int](Range(0, n), WithParallelism(parallelism)),
+			collector.NewQuantileCollector(collector.Identify[int](), 0),
+		)
+		require.Equal(t, int64(n), sketch.Count())
+
+		quantiles := []float64{0, 0.5, 0.95, 0.99, 1}
+		for i, value := range sketch.Quantiles(quantiles...) {
+			// the rank error of the default sketch is about 1%
+			require.InDelta(t, quantiles[i]*n, value, 0.02*n, "quantile %v", quantiles[i])
+		}
+		require.Equal(t, float64(0), sketch.Quantile(0))
+		require.Equal(t, float64(n-1), sketch.Quantile(1))
+	}
+
+	require.True(t, math.IsNaN(collector.NewQuantileSketch(0).Quantile(0.5)))
+}
+
+func TestCollector_TopK(t *testing.T) {
+	items := Range(0, 1000).ToIfaceSlice()
+	rand.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+	less := func(a, b int) bool {
+		return a < b
+	}
+
+	for _, parallelism := range []uint{1, 4} {
+		numbers := func() TypedStream[int] {
+			return OfStream[int](Just(items), WithParallelism(parallelism))
+		}
+		require.Equal(t, []int{999, 998, 997}, Collect(numbers(), collector.NewTopKCollector(3, less)))
+		require.Equal(t, []int{0, 1, 2}, Collect(numbers(), collector.NewBottomKCollector(3, less)))
+		require.Empty(t, Collect(numbers(), collector.NewTopKCollector(0, less)))
+	}
+
+	words := []string{"go", "stream", "a", "collector", "top"}
+	require.Equal(t, []string{"collector", "stream"}, Collect(Of(words), collector.TopKBy(2, func(word string) int {
+		return len(word)
+	})))
+	require.Equal(t, []string{"a", "go"}, Collect(Of(words), collector.BottomKBy(2, func(word string) int {
+		return len(word)
+	})))
+	require.Equal(t, []string{"go", "a"}, Collect(Of([]string{"go", "a"}), collector.TopKBy(5, func(word string) int {
+		return len(word)
+	})))
+}
+
+func TestCollector_HyperLogLog(t *testing.T) {
+	for _, parallelism := range []uint{1, 4} {
+		hll := Collect(OfStream[int](Range(0, 100000).Concat([]Stream{Range(0, 50000)}), WithParallelism(parallelism)),
+			collector.NewHyperLogLogCollector[int](14, nil),
+		)
+		require.InDelta(t, 100000, hll.Count(), 3000)
+
+		data, err := hll.MarshalBinary()
+		require.NoError(t, err)
+		restored := collector.NewHyperLogLog[int](4, nil)
+		require.NoError(t, restored.UnmarshalBinary(data))
+		require.Equal(t, hll.Count(), restored.Count())
+	}
+
+	small := Collect(Of([]string{"a", "b", "a", "c"}), collector.NewHyperLogLogCollector[string](10, nil))
+	require.Equal(t, uint64(3), small.Count())
+	require.Error(t, small.UnmarshalBinary([]byte{1, 10, 0}))
+}
+
+func TestCollector_BloomFilter(t *testing.T) {
+	bf := Collect(OfStream[int](Range(0, 10000), WithParallelism(4)), collector.NewBloomFilterCollector[int](10000, 0.01))
+	for i := 0; i < 10000; i++ {
+		require.True(t, bf.Contains(i))
+	}
+	falsePositives := 0
+	for i := 10000; i < 20000; i++ {
+		if bf.Contains(i) {
+			falsePositives++
+		}
+	}
+	require.Less(t, falsePositives, 200)
+
+	data, err := bf.MarshalBinary()
+	require.NoError(t, err)
+	restored := new(collector.BloomFilter[int])
+	require.NoError(t, restored.UnmarshalBinary(data))
+	require.Equal(t, bf.Count(), restored.Count())
+	for i := 0; i < 10000; i++ {
+		require.True(t, restored.Contains(i))
+	}
+	require.Error(t, restored.UnmarshalBinary(data[:len(data)-1]))
+}
+
+func TestCollector_Frequency(t *testing.T) {
+	words := []string{"a", "b", "a", "c", "a", "b"}
+	for _, parallelism := range []uint{1, 4} {
+		require.Equal(t, map[string]int{"a": 3, "b": 2, "c": 1}, Collect(
+			Of(words, WithParallelism(parallelism)),
+			collector.NewFrequencyCollector[string](collector.Identify[string]()),
+		))
+	}
+}
+
+func TestCollector_Histogram(t *testing.T) {
+	h := Collect(OfStream[int](Range(1, 101), WithParallelism(4)),
+		collector.NewHistogramCollector[int](collector.Identify[int](), collector.LinearBuckets(25, 25, 3)),
+	)
+	require.Equal(t, []float64{25, 50, 75}, h.Bounds)
+	require.Equal(t, []int64{25, 25, 25, 25}, h.Counts)
+	require.Equal(t, int64(100), h.Count)
+	require.Equal(t, float64(5050), h.Sum)
+
+	h = Collect(Of([]float64{0.5, 1, 3, 4, 100}),
+		collector.NewHistogramCollector[float64](collector.Identify[float64](), collector.ExponentialBuckets(1, 2, 3)),
+	)
+	require.Equal(t, []float64{1, 2, 4}, h.Bounds)
+	require.Equal(t, []int64{2, 0, 2, 1}, h.Counts)
+
+	require.Panics(t, func() {
+		collector.NewHistogramCollector[int](collector.Identify[int](), []float64{2, 1})
+	})
+}
+
+func TestCollector_CountMinSketch(t *testing.T) {
+	// item i occurs i times for i in [1, 100] but the heavy items in [96, 100] occur 100 times more,
+	// and 10000 noise items occur once
+	counts := make(map[int]int)
+	var items []int
+	for i := 1; i <= 100; i++ {
+		counts[i] = i
+		if i > 95 {
+			counts[i] *= 100
+		}
+		for j := 0; j < counts[i]; j++ {
+			items = append(items, i)
+		}
+	}
+	for i := 1000; i < 11000; i++ {
+		items = append(items, i)
+	}
+	rand.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+
+	sketch := Collect(Of(items, WithParallelism(4)), collector.NewCountMinSketchCollector[int](0.001, 0.01, nil))
+	require.Equal(t, uint64(len(items)), sketch.Total())
+	for i := 1; i <= 100; i++ {
+		estimate := sketch.Estimate(i)
+		require.GreaterOrEqual(t, estimate, uint64(counts[i]))
+		require.LessOrEqual(t, estimate, uint64(counts[i])+uint64(0.001*float64(len(items))))
+	}
+
+	for _, parallelism := range []uint{1, 4} {
+		hitters := Collect(Of(items, WithParallelism(parallelism)),
+			collector.NewHeavyHittersCollector[int](collector.Identify[int](), 5, 0.001, 0.01),
+		)
+		require.Len(t, hitters, 5)
+		for i, hitter := range hitters {
+			require.Equal(t, 100-i, hitter.Item)
+			require.GreaterOrEqual(t, hitter.Count, uint64(counts[100-i]))
+		}
+	}
+}
+
+type hashStringer struct {
+	id int
+}
+
+func (s hashStringer) String() string {
+	panic("String must not be called")
+}
+
+func TestCollector_Hash(t *testing.T) {
+	// the values of different types or with ambiguous formats must not collide
+	require.NotEqual(t, collector.Hash[any](int8(1)), collector.Hash[any]("1"))
+	require.NotEqual(t, collector.Hash[any](int8(1)), collector.Hash[any](int16(1)))
+	require.NotEqual(t, collector.Hash([2]string{"a b", ""}), collector.Hash([2]string{"a", "b "}))
+	require.NotEqual(t, collector.Hash([]string{"ab"}), collector.Hash([]string{"a", "b"}))
+	require.NotEqual(t, collector.Hash(true), collector.Hash(false))
+	require.NotEqual(t, collector.Hash(complex(1, 2)), collector.Hash(complex(2, 1)))
+	require.NotEqual(t, collector.Hash(hashStringer{id: 1}), collector.Hash(hashStringer{id: 2}))
+
+	// the equal values have the same hash
+	require.Equal(t, collector.Hash[any](1), collector.Hash(1))
+	require.Equal(t, collector.Hash[any](int8(1)), collector.Hash(int8(1)))
+	require.Equal(t, collector.Hash(math.Copysign(0, -1)), collector.Hash(0.0))
+	require.Equal(t, collector.Hash(hashStringer{id: 1}), collector.Hash(hashStringer{id: 1}))
+	require.Equal(t,
+		collector.Hash(map[string]int{"a": 1, "b": 2, "c": 3}),
+		collector.Hash(map[string]int{"c": 3, "b": 2, "a": 1}),
+	)
+	require.Equal(t, collector.Hash([]any{1, "a", nil}), collector.Hash([]any{1, "a", nil}))
+	require.Equal(t, collector.Hash[any](nil), collector.Hash[fmt.Stringer](nil))
+}
@@ -0,0 +1,171 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ZipFunc combines the items at the same index of two streams
+type ZipFunc func(a, b any) any
+
+// Pair is a pair of items at the same index of two streams
+type Pair[L any, R any] struct {
+	Left  L
+	Right R
+}
+
+// Zip returns a stream of Pair[any, any], which pairs the items at the same index of the given streams.
+//
+// The returned stream ends when either stream ends, the other stream is stopped.
+func Zip(a, b Stream, opts ...Option) Stream {
+	return ZipWith(a, b, func(a, b any) any {
+		return Pair[any, any]{Left: a, Right: b}
+	}, opts...)
+}
+
+// ZipWith returns a stream of the results of the zipper applied to the items at the same index of the given streams.
+//
+// The returned stream ends when either stream ends, the other stream is stopped.
+func ZipWith(a, b Stream, zipper ZipFunc, opts ...Option) Stream {
+	return generate(func(ctx context.Context, p *pipeline, source chan<- any) {
+		subA, subB := subscribe(p, a), subscribe(p, b)
+		defer subA.abandon()
+		defer subB.abandon()
+
+		for {
+			itemA, ok := subA.receive(ctx)
+			if !ok {
+				return
+			}
+			itemB, ok := subB.receive(ctx)
+			if !ok {
+				return
+			}
+			select {
+			case source <- zipper(itemA, itemB):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}, opts...)
+}
+
+// Interleave returns a stream which takes an item from each of the given streams in turn.
+//
+// The streams that end are skipped, the returned stream ends when all streams end.
+func Interleave(streams []Stream, opts ...Option) Stream {
+	return generate(func(ctx context.Context, p *pipeline, source chan<- any) {
+		subs := make([]*subscription, 0, len(streams))
+		for _, s := range streams {
+			sub := subscribe(p, s)
+			defer sub.abandon()
+			subs = append(subs, sub)
+		}
+
+		for len(subs) > 0 {
+			active := subs[:0]
+			for _, sub := range subs {
+				item, ok := sub.receive(ctx)
+				if !ok {
+					if ctx.Err() != nil {
+						return
+					}
+					continue
+				}
+				select {
+				case source <- item:
+				case <-ctx.Done():
+					return
+				}
+				active = append(active, sub)
+			}
+			subs = active
+		}
+	}, opts...)
+}
+
+// Merge returns a stream of the items of the given streams in the order they arrive.
+//
+// The returned stream ends when all streams end.
+func Merge(streams []Stream, opts ...Option) Stream {
+	return generate(func(ctx context.Context, p *pipeline, source chan<- any) {
+		wg := new(sync.WaitGroup)
+		wg.Add(len(streams))
+		for _, s := range streams {
+			sub := subscribe(p, s)
+			defer sub.abandon()
+
+			go func(sub *subscription) {
+				defer wg.Done()
+
+				for item, ok := sub.receive(ctx); ok; item, ok = sub.receive(ctx) {
+					select {
+					case source <- item:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}(sub)
+		}
+		wg.Wait()
+	}, opts...)
+}
+
+// subscription forwards the items of a stream to a channel,
+// so that the items of several streams can be received one by one.
+type subscription struct {
+	s         Stream
+	items     chan any
+	done      chan struct{}
+	once      sync.Once
+	abandoned int32
+}
+
+// subscribe consumes the given stream in a new goroutine,
+// the error of the stream is recorded into p unless the subscription is abandoned.
+func subscribe(p *pipeline, s Stream) *subscription {
+	sub := &subscription{
+		s:     s,
+		items: make(chan any),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(sub.items)
+		defer func() { p.handlePanic(recover(), nil) }()
+
+		s.ForEach(func(item any) {
+			select {
+			case sub.items <- item:
+			case <-sub.done:
+			}
+		})
+		if err := s.Err(); err != nil && atomic.LoadInt32(&sub.abandoned) == 0 {
+			p.fail(err)
+		}
+	}()
+	return sub
+}
+
+// receive receives the next item of the stream.
+//
+// ok is false if the stream ends or ctx is done.
+func (sub *subscription) receive(ctx context.Context) (item any, ok bool) {
+	select {
+	case item, ok = <-sub.items:
+		return item, ok
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// abandon stops the stream, its remaining items and error are discarded
+func (sub *subscription) abandon() {
+	sub.once.Do(func() {
+		atomic.StoreInt32(&sub.abandoned, 1)
+		close(sub.done)
+		if cs, ok := sub.s.(*concurrentStream); ok {
+			cs.pipeline.stop()
+		}
+	})
+}
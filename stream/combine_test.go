@@ -0,0 +1,79 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestZip(t *testing.T) {
+	generatorDone := make(chan struct{})
+	infinite := FromContext(func(ctx context.Context, source chan<- any) {
+		defer close(generatorDone)
+		for i := 0; ; i++ {
+			select {
+			case source <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	require.Equal(t, []any{
+		Pair[any, any]{Left: "a", Right: 0},
+		Pair[any, any]{Left: "b", Right: 1},
+		Pair[any, any]{Left: "c", Right: 2},
+	}, Zip(Just([]string{"a", "b", "c"}), infinite).ToIfaceSlice())
+
+	select {
+	case <-generatorDone:
+	case <-time.After(time.Second):
+		require.Fail(t, "the longer stream is not stopped")
+	}
+}
+
+func TestZipWith(t *testing.T) {
+	actualItems := ZipWith(Range(0, 4, WithParallelism(4), WithOrdered()), Range(10, 20), func(a, b any) any {
+		return a.(int) + b.(int)
+	}).ToIfaceSlice()
+	require.Equal(t, []any{10, 12, 14, 16}, actualItems)
+
+	errBoom := errors.New("boom")
+	s := ZipWith(Range(0, 4).MapE(func(item any) (any, error) {
+		if item.(int) == 2 {
+			return nil, errBoom
+		}
+		return item, nil
+	}), Range(10, 20), func(a, b any) any {
+		return a.(int) + b.(int)
+	})
+	// the items before the failed one may be dropped once the stream stops
+	require.Subset(t, []any{10, 12}, s.ToIfaceSlice())
+	require.ErrorIs(t, s.Err(), errBoom)
+}
+
+func TestInterleave(t *testing.T) {
+	actualItems := Interleave([]Stream{
+		Just([]any{1, 2, 3}),
+		Just([]any{"a"}),
+		Just([]any{"x", "y"}),
+	}).ToIfaceSlice()
+	require.Equal(t, []any{1, "a", "x", 2, "y", 3}, actualItems)
+
+	require.Empty(t, Interleave(nil).ToIfaceSlice())
+}
+
+func TestMerge(t *testing.T) {
+	actualItems := Merge([]Stream{
+		Range(0, 100),
+		Range(100, 200, WithParallelism(4)),
+		Just([]int{}),
+	}).ToIfaceSlice()
+	require.ElementsMatch(t, Range(0, 200).ToIfaceSlice(), actualItems)
+
+	item, found := Merge([]Stream{Range[int](0, 1e10), Range[int](0, 1e10)}).FindFirst()
+	require.True(t, found)
+	require.Equal(t, 0, item)
+}
@@ -1,17 +1,29 @@
 package stream
 
 import (
+	"context"
+	"github.com/carter-ya/go-tools/stream/collector"
 	"math"
-	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 var _ Stream = (*concurrentStream)(nil)
 
+// defaultDistinctApproxCapacity is the capacity of the first Bloom filter of DistinctApprox
+const defaultDistinctApproxCapacity = 1024
+
 type concurrentStream struct {
 	source      <-chan any
 	parallelism uint
+	ordered     bool
+	ctx         context.Context
+	pipeline    *pipeline
+	err         error
+
+	sortMaxItems int
+	sortTempDir  string
 }
 
 // WithSync returns an option that sets the sync of the stream
@@ -33,39 +45,161 @@ func WithParallelism(parallelism uint) func(Stream) {
 	}
 }
 
+// WithOrdered returns an option that keeps the encounter order of the items in parallel stages.
+//
+// The results of the parallel workers are reordered by a buffer bounded by the parallelism,
+// and the terminal operations consume the items one by one in encounter order.
+func WithOrdered() Option {
+	return func(s Stream) {
+		if cs, ok := s.(*concurrentStream); ok {
+			cs.ordered = true
+		} else {
+			panic("stream: WithOrdered must be used with concurrentStream")
+		}
+	}
+}
+
+// WithContext returns an option that binds the stream to the given context.
+//
+// Once the context is done, the generator, all workers and the terminal operation stop,
+// and Err of the stream returns ctx.Err().
+//
+// The context is inherited by all streams derived from the stream,
+// so it is usually given when the stream is created.
+func WithContext(ctx context.Context) Option {
+	if ctx == nil {
+		panic("nil context")
+	}
+	return func(s Stream) {
+		if cs, ok := s.(*concurrentStream); ok {
+			cs.pipeline.setParent(ctx)
+			cs.ctx = cs.pipeline.derive()
+		} else {
+			panic("stream: WithContext must be used with concurrentStream")
+		}
+	}
+}
+
+// WithErrorPolicy returns an option that sets the error policy of the stream,
+// the default policy is FailFast.
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(s Stream) {
+		if cs, ok := s.(*concurrentStream); ok {
+			cs.pipeline.setPolicy(policy)
+		} else {
+			panic("stream: WithErrorPolicy must be used with concurrentStream")
+		}
+	}
+}
+
+// WithPanicPolicy returns an option that sets the panic policy of the stream,
+// the default policy is RethrowPanics.
+func WithPanicPolicy(policy PanicPolicy) Option {
+	return func(s Stream) {
+		if cs, ok := s.(*concurrentStream); ok {
+			cs.pipeline.setPanicPolicy(policy)
+		} else {
+			panic("stream: WithPanicPolicy must be used with concurrentStream")
+		}
+	}
+}
+
 func (cs *concurrentStream) newStream(source <-chan any) *concurrentStream {
-	return &concurrentStream{source, cs.parallelism}
+	return &concurrentStream{
+		source:      source,
+		parallelism: cs.parallelism,
+		ordered:     cs.ordered,
+		ctx:         cs.ctx,
+		pipeline:    cs.pipeline,
+
+		sortMaxItems: cs.sortMaxItems,
+		sortTempDir:  cs.sortTempDir,
+	}
+}
+
+// fork returns a stream with a new pipeline which inherits the context, policy and errors of the current one,
+// so that the current pipeline can be finished while the new one keeps going.
+//
+// The caller is responsible for setting the source of the returned stream.
+func (cs *concurrentStream) fork() *concurrentStream {
+	p := cs.pipeline.fork()
+	return &concurrentStream{
+		parallelism: cs.parallelism,
+		ordered:     cs.ordered,
+		ctx:         p.derive(),
+		pipeline:    p,
+
+		sortMaxItems: cs.sortMaxItems,
+		sortTempDir:  cs.sortTempDir,
+	}
 }
 
 func (cs *concurrentStream) newStreamWithSlice(slice []any) *concurrentStream {
+	ns := cs.fork()
 	out := make(chan any, cs.parallelism)
 	go func() {
 		defer close(out)
 		for _, item := range slice {
-			out <- item
+			if !ns.send(out, item) {
+				return
+			}
 		}
 	}()
-	return cs.newStream(out)
+	ns.source = out
+	return ns
 }
 
 func (cs *concurrentStream) Map(mapper MapFunc, opts ...Option) Stream {
 	return cs.doStream(func(item any, out chan<- any) {
-		out <- mapper(item)
+		cs.send(out, mapper(item))
+	}, opts...)
+}
+
+func (cs *concurrentStream) MapE(mapper MapEFunc, opts ...Option) Stream {
+	return cs.doStream(func(item any, out chan<- any) {
+		mapped, err := mapper(item)
+		if err != nil {
+			cs.pipeline.fail(err)
+			return
+		}
+		cs.send(out, mapped)
 	}, opts...)
 }
 
 func (cs *concurrentStream) FlatMap(mapper FlatMapFunc, opts ...Option) Stream {
 	return cs.doStream(func(item any, out chan<- any) {
-		mapper(item).ForEach(func(each any) {
-			out <- each
-		})
+		cs.flatten(mapper(item), out)
+	}, opts...)
+}
+
+func (cs *concurrentStream) FlatMapE(mapper FlatMapEFunc, opts ...Option) Stream {
+	return cs.doStream(func(item any, out chan<- any) {
+		s, err := mapper(item)
+		if err != nil {
+			cs.pipeline.fail(err)
+			return
+		}
+		cs.flatten(s, out)
 	}, opts...)
 }
 
 func (cs *concurrentStream) Filter(filter FilterFunc, opts ...Option) Stream {
 	return cs.doStream(func(item any, out chan<- any) {
 		if filter(item) {
-			out <- item
+			cs.send(out, item)
+		}
+	}, opts...)
+}
+
+func (cs *concurrentStream) FilterE(filter FilterEFunc, opts ...Option) Stream {
+	return cs.doStream(func(item any, out chan<- any) {
+		matched, err := filter(item)
+		if err != nil {
+			cs.pipeline.fail(err)
+			return
+		}
+		if matched {
+			cs.send(out, item)
 		}
 	}, opts...)
 }
@@ -75,17 +209,20 @@ func (cs *concurrentStream) Concat(streams []Stream, opts ...Option) Stream {
 
 	concatStreams := append([]Stream{cs}, streams...)
 
-	if !cs.isParallel() {
+	// each stream is consumed by a terminal operation, so the concatenated stream needs its own pipeline
+	ns := cs.fork()
+	if !cs.isParallel() || cs.ordered {
 		out := make(chan any)
 		go func() {
 			defer close(out)
+			defer func() { ns.pipeline.handlePanic(recover(), nil) }()
+
 			for _, s := range concatStreams {
-				s.ForEach(func(item any) {
-					out <- item
-				})
+				ns.flatten(s, out)
 			}
 		}()
-		return cs.newStream(out)
+		ns.source = out
+		return ns
 	}
 
 	out := make(chan any, cs.parallelism)
@@ -99,9 +236,9 @@ func (cs *concurrentStream) Concat(streams []Stream, opts ...Option) Stream {
 				defer func() {
 					<-semaphore
 				}()
-				s.ForEach(func(item any) {
-					out <- item
-				})
+				defer func() { ns.pipeline.handlePanic(recover(), nil) }()
+
+				ns.flatten(s, out)
 			}(s)
 		}
 
@@ -110,34 +247,76 @@ func (cs *concurrentStream) Concat(streams []Stream, opts ...Option) Stream {
 			semaphore <- struct{}{}
 		}
 	}()
-	return cs.newStream(out)
+	ns.source = out
+	return ns
 }
 
 func (cs *concurrentStream) Sort(less LessFunc, opts ...Option) Stream {
-	iface := cs.ToIfaceSlice(opts...)
-	sort.Slice(iface, func(i, j int) bool {
-		return less(iface[i], iface[j])
-	})
+	return cs.sort(less, false, opts...)
+}
+
+func (cs *concurrentStream) SortStable(less LessFunc, opts ...Option) Stream {
+	return cs.sort(less, true, opts...)
+}
+
+// sort materializes the stream and sorts it in parallel if the stream is parallel
+func (cs *concurrentStream) sort(less LessFunc, stable bool, opts ...Option) Stream {
+	cs.applyOptions(opts...)
+	parallelism := cs.parallelism
+	iface := cs.ToIfaceSlice()
+	parallelSort(iface, less, parallelism, stable)
 	return cs.newStreamWithSlice(iface)
 }
 
 func (cs *concurrentStream) Distinct(distinct DistinctFunc, opts ...Option) Stream {
 	cs.applyOptions(opts...)
-	if !cs.isParallel() {
+	if !cs.isParallel() || cs.ordered {
+		// deduplicate in a single goroutine to keep the first occurrence
 		seen := make(map[any]struct{})
-		return cs.doStream(func(item any, out chan<- any) {
-			if _, ok := seen[item]; !ok {
-				seen[item] = struct{}{}
-				out <- item
+		return cs.doStreamWithOptionSync(func(item any, out chan<- any) {
+			key := distinct(item)
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				cs.send(out, item)
 			}
-		})
+		}, false)
 	}
 
 	seen := new(sync.Map)
 	return cs.doStream(func(item any, out chan<- any) {
 		_, loaded := seen.LoadOrStore(distinct(item), struct{}{})
 		if !loaded {
-			out <- item
+			cs.send(out, item)
+		}
+	})
+}
+
+func (cs *concurrentStream) DistinctApprox(distinct DistinctFunc, fpRate float64, opts ...Option) Stream {
+	cs.applyOptions(opts...)
+	filter := collector.NewScalableBloomFilter[any](defaultDistinctApproxCapacity, fpRate, nil)
+	if !cs.isParallel() || cs.ordered {
+		return cs.doStreamWithOptionSync(func(item any, out chan<- any) {
+			hash := collector.Hash(distinct(item))
+			if !filter.ContainsHash(hash) {
+				filter.AddHash(hash)
+				cs.send(out, item)
+			}
+		}, false)
+	}
+
+	mu := new(sync.Mutex)
+	return cs.doStream(func(item any, out chan<- any) {
+		hash := collector.Hash(distinct(item))
+
+		mu.Lock()
+		seen := filter.ContainsHash(hash)
+		if !seen {
+			filter.AddHash(hash)
+		}
+		mu.Unlock()
+
+		if !seen {
+			cs.send(out, item)
 		}
 	})
 }
@@ -149,13 +328,14 @@ func (cs *concurrentStream) Limit(limit int64, opts ...Option) Stream {
 	go func() {
 		defer close(out)
 
-		for item := range cs.source {
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
 			if limit <= 0 {
-				go cs.drain()
 				break
 			}
 			limit--
-			out <- item
+			if !cs.send(out, item) {
+				break
+			}
 		}
 	}()
 	return cs.newStream(out)
@@ -168,11 +348,13 @@ func (cs *concurrentStream) Skip(limit int64, opts ...Option) Stream {
 	go func() {
 		defer close(out)
 
-		for item := range cs.source {
-			if limit <= 0 {
-				out <- item
-			} else {
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+			if limit > 0 {
 				limit--
+				continue
+			}
+			if !cs.send(out, item) {
+				break
 			}
 		}
 	}()
@@ -186,11 +368,15 @@ func (cs *concurrentStream) TakeWhile(match MatchFunc, opts ...Option) Stream {
 	go func() {
 		defer close(out)
 
-		for item := range cs.source {
-			if match(item) {
-				out <- item
-			} else {
-				go cs.drain()
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+			matched, ok := cs.safeMatch(match, item)
+			if !ok {
+				continue
+			}
+			if !matched {
+				break
+			}
+			if !cs.send(out, item) {
 				break
 			}
 		}
@@ -206,12 +392,17 @@ func (cs *concurrentStream) DropWhile(match MatchFunc, opts ...Option) Stream {
 		defer close(out)
 
 		dropping := true
-		for item := range cs.source {
-			if dropping && match(item) {
-				continue
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+			if dropping {
+				matched, ok := cs.safeMatch(match, item)
+				if !ok || matched {
+					continue
+				}
 			}
-			out <- item
 			dropping = false
+			if !cs.send(out, item) {
+				break
+			}
 		}
 	}()
 	return cs.newStream(out)
@@ -220,26 +411,96 @@ func (cs *concurrentStream) DropWhile(match MatchFunc, opts ...Option) Stream {
 func (cs *concurrentStream) Peek(consumer ConsumeFunc, opts ...Option) Stream {
 	return cs.doStream(func(item any, out chan<- any) {
 		consumer(item)
-		out <- item
+		cs.send(out, item)
 	}, opts...)
 }
 
+func (cs *concurrentStream) Window(size int, opts ...Option) Stream {
+	return cs.SlidingWindow(size, size, opts...)
+}
+
+func (cs *concurrentStream) SlidingWindow(size, step int, opts ...Option) Stream {
+	if size <= 0 {
+		panic("invalid window size")
+	}
+	if step <= 0 {
+		panic("invalid window step")
+	}
+	cs.applyOptions(opts...)
+
+	out := make(chan any, cs.parallelism)
+	go func() {
+		defer close(out)
+
+		window := make([]any, 0, size)
+		// fresh is the number of items in the window that are not emitted yet
+		fresh := 0
+		// skip is the number of items to skip before the next window, it is positive if step > size
+		skip := 0
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			window = append(window, item)
+			fresh++
+			if len(window) < size {
+				continue
+			}
+			if !cs.send(out, window) {
+				return
+			}
+
+			next := make([]any, 0, size)
+			if step < size {
+				next = append(next, window[step:]...)
+			} else {
+				skip = step - size
+			}
+			window = next
+			fresh = 0
+		}
+		if fresh > 0 {
+			cs.send(out, window)
+		}
+	}()
+	return cs.newStream(out)
+}
+
+func (cs *concurrentStream) TimeWindow(size int, duration time.Duration, opts ...Option) Stream {
+	if size <= 0 {
+		panic("invalid window size")
+	}
+	if duration <= 0 {
+		panic("invalid window duration")
+	}
+	return cs.timedWindow(size, duration, false, opts...)
+}
+
+func (cs *concurrentStream) SessionWindow(gap time.Duration, opts ...Option) Stream {
+	if gap <= 0 {
+		panic("invalid session gap")
+	}
+	return cs.timedWindow(0, gap, true, opts...)
+}
+
 func (cs *concurrentStream) AnyMatch(match MatchFunc, opts ...Option) bool {
 	cs.applyOptions(opts...)
 
 	if !cs.isParallel() {
-		for item := range cs.source {
-			if match(item) {
-				go cs.drain()
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+			if matched, _ := cs.safeMatch(match, item); matched {
+				cs.finish()
 				return true
 			}
 		}
+		cs.finish()
 		return false
 	}
 
 	semaphore := make(chan struct{}, cs.parallelism)
 	var result int32 = 0
-	for item := range cs.source {
+	for item, ok := cs.receive(); ok; item, ok = cs.receive() {
 		semaphore <- struct{}{}
 		if atomic.LoadInt32(&result) == 0 {
 			go func(item any) {
@@ -247,13 +508,12 @@ func (cs *concurrentStream) AnyMatch(match MatchFunc, opts ...Option) bool {
 					<-semaphore
 				}()
 
-				if match(item) {
+				if matched, _ := cs.safeMatch(match, item); matched {
 					atomic.StoreInt32(&result, 1)
 				}
 			}(item)
 		} else {
 			<-semaphore
-			go cs.drain()
 			break
 		}
 	}
@@ -261,6 +521,7 @@ func (cs *concurrentStream) AnyMatch(match MatchFunc, opts ...Option) bool {
 	for i := 0; uint(i) < cs.parallelism; i++ {
 		semaphore <- struct{}{}
 	}
+	cs.finish()
 	return atomic.LoadInt32(&result) == 1
 }
 
@@ -268,18 +529,19 @@ func (cs *concurrentStream) AllMatch(match MatchFunc, opts ...Option) bool {
 	cs.applyOptions(opts...)
 
 	if !cs.isParallel() {
-		for item := range cs.source {
-			if !match(item) {
-				go cs.drain()
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+			if matched, ok := cs.safeMatch(match, item); ok && !matched {
+				cs.finish()
 				return false
 			}
 		}
+		cs.finish()
 		return true
 	}
 
 	semaphore := make(chan struct{}, cs.parallelism)
 	var result int32 = 1
-	for item := range cs.source {
+	for item, ok := cs.receive(); ok; item, ok = cs.receive() {
 		semaphore <- struct{}{}
 		if atomic.LoadInt32(&result) == 1 {
 			go func(item any) {
@@ -287,16 +549,20 @@ func (cs *concurrentStream) AllMatch(match MatchFunc, opts ...Option) bool {
 					<-semaphore
 				}()
 
-				if !match(item) {
+				if matched, ok := cs.safeMatch(match, item); ok && !matched {
 					atomic.StoreInt32(&result, 0)
 				}
 			}(item)
 		} else {
 			<-semaphore
-			go cs.drain()
 			break
 		}
 	}
+	// wait for all goroutines to finish
+	for i := 0; uint(i) < cs.parallelism; i++ {
+		semaphore <- struct{}{}
+	}
+	cs.finish()
 	return atomic.LoadInt32(&result) == 1
 }
 
@@ -304,18 +570,19 @@ func (cs *concurrentStream) NoneMatch(match MatchFunc, opts ...Option) bool {
 	cs.applyOptions(opts...)
 
 	if !cs.isParallel() {
-		for item := range cs.source {
-			if match(item) {
-				go cs.drain()
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+			if matched, _ := cs.safeMatch(match, item); matched {
+				cs.finish()
 				return false
 			}
 		}
+		cs.finish()
 		return true
 	}
 
 	semaphore := make(chan struct{}, cs.parallelism)
 	var result int32 = 1
-	for item := range cs.source {
+	for item, ok := cs.receive(); ok; item, ok = cs.receive() {
 		semaphore <- struct{}{}
 		if atomic.LoadInt32(&result) == 1 {
 			go func(item any) {
@@ -323,27 +590,29 @@ func (cs *concurrentStream) NoneMatch(match MatchFunc, opts ...Option) bool {
 					<-semaphore
 				}()
 
-				if match(item) {
+				if matched, _ := cs.safeMatch(match, item); matched {
 					atomic.StoreInt32(&result, 0)
 				}
 			}(item)
 		} else {
 			<-semaphore
-			go cs.drain()
 			break
 		}
 	}
+	// wait for all goroutines to finish
+	for i := 0; uint(i) < cs.parallelism; i++ {
+		semaphore <- struct{}{}
+	}
+	cs.finish()
 	return atomic.LoadInt32(&result) == 1
 }
 
 func (cs *concurrentStream) FindFirst(opts ...Option) (item any, found bool) {
 	cs.applyOptions(opts...)
 
-	for item = range cs.source {
-		go cs.drain()
-		return item, true
-	}
-	return nil, false
+	item, found = cs.receive()
+	cs.finish()
+	return item, found
 }
 
 func (cs *concurrentStream) Count(opts ...Option) int64 {
@@ -367,12 +636,35 @@ func (cs *concurrentStream) Reduce(identity any, accumulator AccumulatorFunc, op
 	return identity
 }
 
+func (cs *concurrentStream) ReduceE(identity any, accumulator AccumulatorEFunc, opts ...Option) (any, error) {
+	cs.doStreamWithTerminate(func(item any) {
+		reduced, err := accumulator(identity, item)
+		if err != nil {
+			cs.pipeline.fail(err)
+			return
+		}
+		identity = reduced
+	},
+		opts...,
+	)
+	return identity, cs.err
+}
+
 func (cs *concurrentStream) ForEach(consumer ConsumeFunc, opts ...Option) {
 	cs.doStreamWithTerminate(func(item any) {
 		consumer(item)
 	}, opts...)
 }
 
+func (cs *concurrentStream) ForEachE(consumer ConsumeEFunc, opts ...Option) error {
+	cs.doStreamWithTerminate(func(item any) {
+		if err := consumer(item); err != nil {
+			cs.pipeline.fail(err)
+		}
+	}, opts...)
+	return cs.err
+}
+
 func (cs *concurrentStream) ToIfaceSlice(opts ...Option) []any {
 	ifaces := cs.Reduce(make([]any, 0), func(identity any, item any) any {
 		return append(identity.([]any), item)
@@ -393,12 +685,76 @@ func (cs *concurrentStream) Collect(
 	finisher func(container any) any,
 ) any {
 	container := supplier()
+	if _, ok := container.(combinable); ok && cs.isParallel() && !cs.ordered {
+		// the collector is a ParallelCollector, the first container is reused by the first worker
+		first := container
+		supply := func() any {
+			if c := first; c != nil {
+				first = nil
+				return c
+			}
+			return supplier()
+		}
+		return cs.CollectWithCombiner(supply, accumulator, func(a, b any) any {
+			return a.(combinable).combine(b)
+		}, finisher)
+	}
+
 	cs.doStreamWithTerminate(func(item any) {
 		accumulator(container, item)
 	})
 	return finisher(container)
 }
 
+func (cs *concurrentStream) CollectWithCombiner(
+	supplier func() any,
+	accumulator func(container, item any),
+	combiner func(a, b any) any,
+	finisher func(container any) any,
+) any {
+	if !cs.isParallel() || cs.ordered {
+		return cs.Collect(supplier, accumulator, finisher)
+	}
+
+	containers := make([]any, cs.parallelism)
+	wg := new(sync.WaitGroup)
+	wg.Add(len(containers))
+	for i := range containers {
+		containers[i] = supplier()
+		go func(container any) {
+			defer wg.Done()
+
+			for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+				cs.safeCall(func(item any, _ chan<- any) {
+					accumulator(container, item)
+				}, item, nil)
+			}
+		}(containers[i])
+	}
+	wg.Wait()
+	cs.finish()
+
+	container := containers[0]
+	for _, other := range containers[1:] {
+		container = combiner(container, other)
+	}
+	return finisher(container)
+}
+
+func (cs *concurrentStream) CollectE(
+	supplier func() any,
+	accumulator func(container, item any) error,
+	finisher func(container any) any,
+) (any, error) {
+	container := supplier()
+	cs.doStreamWithTerminate(func(item any) {
+		if err := accumulator(container, item); err != nil {
+			cs.pipeline.fail(err)
+		}
+	})
+	return finisher(container), cs.err
+}
+
 func (cs *concurrentStream) Close() {
 	cs.doStreamWithTerminate(func(item any) {})
 }
@@ -424,9 +780,12 @@ func (cs *concurrentStream) doStreamWithOption(
 	opts ...Option,
 ) *concurrentStream {
 	cs.applyOptions(opts...)
-	if !cs.isParallel() {
+	if !cs.isParallel() || (terminate && cs.ordered) {
 		return cs.doStreamWithOptionSync(fn, terminate)
 	}
+	if cs.ordered {
+		return cs.doStreamOrdered(fn)
+	}
 
 	var out chan any
 	var wg *sync.WaitGroup
@@ -447,14 +806,14 @@ func (cs *concurrentStream) doStreamWithOption(
 		}()
 
 		semaphore := make(chan struct{}, cs.parallelism)
-		for item := range cs.source {
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
 			// acquire semaphore
 			semaphore <- struct{}{}
 			go func(item any) {
 				// release semaphore
 				defer func() { <-semaphore }()
 
-				fn(item, out)
+				cs.safeCall(fn, item, out)
 			}(item)
 		}
 
@@ -466,21 +825,21 @@ func (cs *concurrentStream) doStreamWithOption(
 
 	if terminate {
 		wg.Wait()
+		cs.finish()
 		return nil
 	} else {
 		return cs.newStream(out)
 	}
 }
 
+// doStreamWithOptionSync is like doStreamWithOption, but fn is executed in a single goroutine
+// regardless of the parallelism
 func (cs *concurrentStream) doStreamWithOptionSync(
 	fn func(item any, out chan<- any),
 	terminate bool,
 	opts ...Option,
 ) *concurrentStream {
 	cs.applyOptions(opts...)
-	if cs.isParallel() {
-		return cs.doStreamWithOption(fn, terminate)
-	}
 
 	var out chan any
 	var wg *sync.WaitGroup
@@ -500,19 +859,117 @@ func (cs *concurrentStream) doStreamWithOptionSync(
 			}
 		}()
 
-		for item := range cs.source {
-			fn(item, out)
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+			cs.safeCall(fn, item, out)
 		}
 	}()
 
 	if terminate {
 		wg.Wait()
+		cs.finish()
 		return nil
 	} else {
 		return cs.newStream(out)
 	}
 }
 
+// doStreamOrdered executes fn in parallel, and emits the results in encounter order.
+//
+// Each item gets a slot in a queue, the worker writes the results to its slot,
+// and the emitter forwards the slots one by one.
+// The semaphore is released only after the slot is forwarded,
+// so the buffered results are bounded by the parallelism.
+func (cs *concurrentStream) doStreamOrdered(fn func(item any, out chan<- any)) *concurrentStream {
+	out := make(chan any, cs.parallelism)
+	semaphore := make(chan struct{}, cs.parallelism)
+	slots := make(chan chan any, cs.parallelism)
+
+	// emitter
+	go func() {
+		defer close(out)
+
+		for slot := range slots {
+			for item := range slot {
+				cs.send(out, item)
+			}
+			// release semaphore
+			<-semaphore
+		}
+	}()
+
+	// dispatcher
+	go func() {
+		defer close(slots)
+
+		for item, ok := cs.receive(); ok; item, ok = cs.receive() {
+			// acquire semaphore
+			semaphore <- struct{}{}
+			slot := make(chan any, 1)
+			slots <- slot
+			go func(item any, slot chan any) {
+				defer close(slot)
+
+				cs.safeCall(fn, item, slot)
+			}(item, slot)
+		}
+	}()
+
+	return cs.newStream(out)
+}
+
+// timedWindow emits a window when it reaches the given size,
+// or the given duration has elapsed since the first item of the window,
+// or since the last item of the window if session is true.
+//
+// size is unlimited if it is 0.
+func (cs *concurrentStream) timedWindow(size int, duration time.Duration, session bool, opts ...Option) Stream {
+	cs.applyOptions(opts...)
+
+	out := make(chan any, cs.parallelism)
+	go func() {
+		defer close(out)
+
+		timer := time.NewTimer(duration)
+		stopTimer(timer)
+		defer timer.Stop()
+
+		var window []any
+		for {
+			select {
+			case item, ok := <-cs.source:
+				if !ok {
+					if len(window) > 0 {
+						cs.send(out, window)
+					}
+					return
+				}
+
+				if len(window) == 0 || session {
+					stopTimer(timer)
+					timer.Reset(duration)
+				}
+				window = append(window, item)
+				if len(window) != size {
+					continue
+				}
+				stopTimer(timer)
+			case <-timer.C:
+				if len(window) == 0 {
+					continue
+				}
+			case <-cs.ctx.Done():
+				return
+			}
+
+			if !cs.send(out, window) {
+				return
+			}
+			window = nil
+		}
+	}()
+	return cs.newStream(out)
+}
+
 func (cs *concurrentStream) isParallel() bool {
 	return cs.parallelism > 1
 }
@@ -523,9 +980,81 @@ func (cs *concurrentStream) applyOptions(opts ...Option) {
 	}
 }
 
-// drain the source
-func (cs *concurrentStream) drain() {
-	for range cs.source {
+func (cs *concurrentStream) Err() error {
+	return cs.err
+}
+
+// receive receives the next item from the source.
+//
+// ok is false if the source is closed or the context is done.
+func (cs *concurrentStream) receive() (item any, ok bool) {
+	select {
+	case item, ok = <-cs.source:
+		return item, ok
+	case <-cs.ctx.Done():
+		return nil, false
+	}
+}
+
+// send sends the item to out, and returns false if the context is done.
+func (cs *concurrentStream) send(out chan<- any, item any) bool {
+	select {
+	case out <- item:
+		return true
+	case <-cs.ctx.Done():
+		return false
+	}
+}
+
+// safeCall calls fn with the item, the panic of fn is handled by the panic policy
+func (cs *concurrentStream) safeCall(fn func(item any, out chan<- any), item any, out chan<- any) {
+	defer func() { cs.pipeline.handlePanic(recover(), item) }()
+
+	fn(item, out)
+}
+
+// safeMatch calls match with the item, the panic of match is handled by the panic policy.
+//
+// ok is false if match panics.
+func (cs *concurrentStream) safeMatch(match MatchFunc, item any) (matched bool, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			cs.pipeline.handlePanic(r, item)
+		}
+	}()
+
+	return match(item), true
+}
+
+// flatten sends all items of the given stream to out,
+// and records the error of the given stream if any.
+func (cs *concurrentStream) flatten(s Stream, out chan<- any) {
+	s.ForEach(func(item any) {
+		cs.send(out, item)
+	})
+	if err := s.Err(); err != nil {
+		cs.pipeline.fail(err)
+	}
+}
+
+// finish records the error of the stream and stops the pipeline,
+// it is called at the end of each terminal operation.
+func (cs *concurrentStream) finish() {
+	cs.err = cs.pipeline.err()
+	if cs.err == nil {
+		cs.err = cs.ctx.Err()
+	}
+	cs.pipeline.stop()
+	cs.pipeline.rethrow()
+}
+
+// stopTimer stops the timer and drains its channel, so that it can be reset safely
+func stopTimer(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
 	}
 }
 
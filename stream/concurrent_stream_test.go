@@ -1,12 +1,17 @@
 package stream
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/carter-ya/go-tools/stream/collector"
 	"github.com/stretchr/testify/require"
 	"math/rand"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestConcurrentStream_Map(t *testing.T) {
@@ -242,6 +247,24 @@ func TestConcurrentStream_Sort(t *testing.T) {
 	}
 }
 
+func TestConcurrentStream_SortStable(t *testing.T) {
+	items := make([]any, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		items = append(items, [2]int{rand.Intn(10), i})
+	}
+
+	for _, parallelism := range []uint{1, 4} {
+		actualItems := Just(items, WithParallelism(parallelism)).SortStable(func(a, b any) bool {
+			return a.([2]int)[0] < b.([2]int)[0]
+		}).ToIfaceSlice()
+		require.Len(t, actualItems, len(items))
+		require.True(t, sort.SliceIsSorted(actualItems, func(i, j int) bool {
+			a, b := actualItems[i].([2]int), actualItems[j].([2]int)
+			return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+		}))
+	}
+}
+
 func TestConcurrentStream_Distinct(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -279,6 +302,28 @@ func TestConcurrentStream_Distinct(t *testing.T) {
 	}
 }
 
+func TestConcurrentStream_DistinctApprox(t *testing.T) {
+	items := Range(0, 10000).Concat([]Stream{Range(0, 10000)}).ToIfaceSlice()
+	for _, parallelism := range []uint{1, 4} {
+		actualItems := Just(items, WithParallelism(parallelism)).DistinctApprox(func(item any) any {
+			return item
+		}, 0.001).ToIfaceSlice()
+
+		seen := make(map[any]struct{}, len(actualItems))
+		for _, item := range actualItems {
+			_, duplicate := seen[item]
+			require.False(t, duplicate)
+			seen[item] = struct{}{}
+		}
+		// a few items may be removed by false positives
+		require.InDelta(t, 10000, len(actualItems), 50)
+	}
+
+	require.Equal(t, []string{"a", "b"}, Of([]string{"a", "b", "a"}).DistinctApprox(func(item string) any {
+		return item
+	}, 0.01).ToSlice())
+}
+
 func TestConcurrentStream_Skip(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -743,6 +788,414 @@ func TestConcurrentStream_Collect(t *testing.T) {
 	}
 }
 
+func TestConcurrentStream_WithContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		parallelism uint
+	}{
+		{
+			name:        "cancel stream with no parallelism",
+			parallelism: 1,
+		},
+		{
+			name:        "cancel stream with parallelism",
+			parallelism: 4,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			generatorDone := make(chan struct{})
+			s := FromContext(func(ctx context.Context, source chan<- any) {
+				defer close(generatorDone)
+				for i := int64(0); ; i++ {
+					select {
+					case source <- i:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}, WithContext(ctx), WithParallelism(test.parallelism)).Map(func(item any) any {
+				return item.(int64) * 2
+			})
+
+			var count int64
+			s.ForEach(func(item any) {
+				if atomic.AddInt64(&count, 1) == 100 {
+					cancel()
+				}
+			})
+			require.ErrorIs(t, s.Err(), context.Canceled)
+
+			select {
+			case <-generatorDone:
+			case <-time.After(time.Second):
+				require.Fail(t, "generator is not stopped")
+			}
+		})
+	}
+}
+
+func TestConcurrentStream_WithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := Range[int64](0, 1e10, WithContext(ctx))
+	require.Empty(t, s.ToIfaceSlice())
+	require.ErrorIs(t, s.Err(), context.Canceled)
+
+	s = Just([]int64{1, 2, 3})
+	require.Equal(t, []any{int64(1), int64(2), int64(3)}, s.ToIfaceSlice())
+	require.NoError(t, s.Err())
+}
+
+func TestConcurrentStream_MapE(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name        string
+		parallelism uint
+	}{
+		{
+			name:        "fail fast with no parallelism",
+			parallelism: 1,
+		},
+		{
+			name:        "fail fast with parallelism",
+			parallelism: 4,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := Range[int64](0, 1e10, WithParallelism(test.parallelism)).MapE(func(item any) (any, error) {
+				if item.(int64) == 100 {
+					return nil, errBoom
+				}
+				return item.(int64) * 2, nil
+			})
+			err := s.ForEachE(func(item any) error {
+				return nil
+			})
+			require.ErrorIs(t, err, errBoom)
+			require.ErrorIs(t, s.Err(), errBoom)
+		})
+	}
+}
+
+func TestConcurrentStream_FilterE(t *testing.T) {
+	s := Range[int64](0, 10, WithErrorPolicy(CollectAllErrors)).FilterE(func(item any) (bool, error) {
+		if item.(int64)%2 == 1 {
+			return false, fmt.Errorf("odd item: %d", item)
+		}
+		return true, nil
+	})
+	require.Equal(t, []any{int64(0), int64(2), int64(4), int64(6), int64(8)}, s.ToIfaceSlice())
+
+	var errs MultiError
+	require.ErrorAs(t, s.Err(), &errs)
+	require.Len(t, errs, 5)
+}
+
+func TestConcurrentStream_FlatMapE(t *testing.T) {
+	errBoom := errors.New("boom")
+	s := Range[int64](0, 10).FlatMapE(func(item any) (Stream, error) {
+		if item.(int64) == 5 {
+			return nil, errBoom
+		}
+		return Just([]any{item, item}), nil
+	})
+	require.Len(t, s.ToIfaceSlice(), 10)
+	require.ErrorIs(t, s.Err(), errBoom)
+
+	s = Range[int64](0, 10).FlatMap(func(item any) Stream {
+		return Just([]any{item}).MapE(func(item any) (any, error) {
+			return nil, errBoom
+		})
+	})
+	require.Empty(t, s.ToIfaceSlice())
+	require.ErrorIs(t, s.Err(), errBoom)
+}
+
+func TestConcurrentStream_ReduceE(t *testing.T) {
+	errBoom := errors.New("boom")
+	sum, err := Range[int64](0, 1000).ReduceE(int64(0), func(identity any, item any) (any, error) {
+		return identity.(int64) + item.(int64), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(499500), sum)
+
+	_, err = Range[int64](0, 1000).ReduceE(int64(0), func(identity any, item any) (any, error) {
+		if item.(int64) == 500 {
+			return nil, errBoom
+		}
+		return identity.(int64) + item.(int64), nil
+	})
+	require.ErrorIs(t, err, errBoom)
+}
+
+func TestConcurrentStream_CollectE(t *testing.T) {
+	errBoom := errors.New("boom")
+	result, err := Range[int64](0, 10, WithErrorPolicy(CollectAllErrors)).CollectE(
+		func() any {
+			return &[]int64{}
+		},
+		func(container, item any) error {
+			if item.(int64) >= 5 {
+				return errBoom
+			}
+			*container.(*[]int64) = append(*container.(*[]int64), item.(int64))
+			return nil
+		},
+		func(container any) any {
+			return *container.(*[]int64)
+		},
+	)
+	require.Equal(t, []int64{0, 1, 2, 3, 4}, result)
+	require.ErrorIs(t, err, errBoom)
+	require.Len(t, err.(MultiError), 5)
+}
+
+func TestConcurrentStream_WithOrdered(t *testing.T) {
+	expectItemsFunc := func(source chan<- any) {
+		for i := 0; i < 200; i++ {
+			source <- int64(i) * 2
+		}
+	}
+	randomSleep := func() {
+		time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+	}
+
+	actualItems := Range[int64](0, 200, WithParallelism(8), WithOrdered()).Map(func(item any) any {
+		randomSleep()
+		return item.(int64) * 2
+	}).ToIfaceSlice()
+	require.Equal(t, From(expectItemsFunc).ToIfaceSlice(), actualItems)
+
+	actualItems = Range[int64](0, 100, WithParallelism(8), WithOrdered()).FlatMap(func(item any) Stream {
+		randomSleep()
+		return Just([]any{item.(int64) * 2, item.(int64)*2 + 1})
+	}).ToIfaceSlice()
+	require.Equal(t, Range[int64](0, 200).ToIfaceSlice(), actualItems)
+
+	actualItems = Range[int64](0, 1000, WithParallelism(8), WithOrdered()).Filter(func(item any) bool {
+		randomSleep()
+		return item.(int64)%3 == 0
+	}).Limit(5).ToIfaceSlice()
+	require.Equal(t, []any{int64(0), int64(3), int64(6), int64(9), int64(12)}, actualItems)
+
+	item, found := Range[int64](0, 1e10, WithParallelism(8), WithOrdered()).Filter(func(item any) bool {
+		randomSleep()
+		return item.(int64) >= 500
+	}).FindFirst()
+	require.True(t, found)
+	require.Equal(t, int64(500), item)
+
+	var consumed []any
+	Range[int64](0, 100, WithParallelism(8), WithOrdered()).ForEach(func(item any) {
+		consumed = append(consumed, item)
+	})
+	require.Equal(t, Range[int64](0, 100).ToIfaceSlice(), consumed)
+
+	actualItems = Just([]int64{3, 1, 3, 2, 1}, WithParallelism(4), WithOrdered()).Distinct(func(item any) any {
+		return item
+	}).ToIfaceSlice()
+	require.Equal(t, []any{int64(3), int64(1), int64(2)}, actualItems)
+}
+
+func TestConcurrentStream_PanicPolicy(t *testing.T) {
+	mapper := func(item any) any {
+		if item.(int64)%10 == 5 {
+			panic("boom")
+		}
+		return item
+	}
+
+	for _, parallelism := range []uint{1, 4} {
+		t.Run(fmt.Sprintf("rethrow panics with parallelism %d", parallelism), func(t *testing.T) {
+			var pe *PanicError
+			func() {
+				defer func() {
+					pe = recover().(*PanicError)
+				}()
+				Range[int64](0, 1e10, WithParallelism(parallelism)).Map(mapper).ForEach(func(item any) {})
+			}()
+			require.NotNil(t, pe)
+			require.Equal(t, "boom", pe.Value)
+			require.Equal(t, int64(5), pe.Item.(int64)%10)
+			require.NotEmpty(t, pe.Stack)
+		})
+
+		t.Run(fmt.Sprintf("return panics with parallelism %d", parallelism), func(t *testing.T) {
+			err := Range[int64](0, 1e10, WithParallelism(parallelism), WithPanicPolicy(ReturnPanics)).
+				Map(mapper).
+				ForEachE(func(item any) error {
+					return nil
+				})
+			var pe *PanicError
+			require.ErrorAs(t, err, &pe)
+			require.Equal(t, "boom", pe.Value)
+		})
+
+		t.Run(fmt.Sprintf("skip panics with parallelism %d", parallelism), func(t *testing.T) {
+			s := Range[int64](0, 100, WithParallelism(parallelism), WithPanicPolicy(SkipPanics)).Map(mapper)
+			require.Len(t, s.ToIfaceSlice(), 90)
+
+			var errs MultiError
+			require.ErrorAs(t, s.Err(), &errs)
+			require.Len(t, errs, 10)
+		})
+	}
+}
+
+func TestConcurrentStream_MatchPanic(t *testing.T) {
+	// the last item panics after the receive loop has ended
+	match := func(result bool) MatchFunc {
+		return func(item any) bool {
+			if item.(int64) == 99 {
+				time.Sleep(50 * time.Millisecond)
+				panic("boom")
+			}
+			return result
+		}
+	}
+	terminals := map[string]func(s Stream) bool{
+		"all match": func(s Stream) bool {
+			return s.AllMatch(match(true))
+		},
+		"none match": func(s Stream) bool {
+			return s.NoneMatch(match(false))
+		},
+	}
+
+	for name, terminal := range terminals {
+		t.Run(name+" rethrows panics", func(t *testing.T) {
+			var pe *PanicError
+			func() {
+				defer func() {
+					pe, _ = recover().(*PanicError)
+				}()
+				terminal(Range[int64](0, 100, WithParallelism(4)))
+			}()
+			require.NotNil(t, pe)
+			require.Equal(t, "boom", pe.Value)
+			require.Equal(t, int64(99), pe.Item)
+		})
+
+		t.Run(name+" returns panics", func(t *testing.T) {
+			s := Range[int64](0, 100, WithParallelism(4), WithPanicPolicy(ReturnPanics))
+			terminal(s)
+			var pe *PanicError
+			require.ErrorAs(t, s.Err(), &pe)
+			require.Equal(t, "boom", pe.Value)
+		})
+	}
+}
+
+func TestConcurrentStream_GeneratorPanic(t *testing.T) {
+	s := From(func(source chan<- any) {
+		source <- 1
+		panic(errors.New("generator failed"))
+	}, WithPanicPolicy(ReturnPanics))
+	require.Equal(t, []any{1}, s.ToIfaceSlice())
+
+	var pe *PanicError
+	require.ErrorAs(t, s.Err(), &pe)
+	require.Nil(t, pe.Item)
+	require.EqualError(t, errors.Unwrap(pe), "generator failed")
+}
+
+func TestConcurrentStream_Window(t *testing.T) {
+	require.Equal(t, []any{
+		[]any{int64(0), int64(1), int64(2)},
+		[]any{int64(3), int64(4), int64(5)},
+		[]any{int64(6)},
+	}, Range[int64](0, 7).Window(3).ToIfaceSlice())
+
+	require.Empty(t, Range[int64](0, 0).Window(3).ToIfaceSlice())
+}
+
+func TestConcurrentStream_SlidingWindow(t *testing.T) {
+	tests := []struct {
+		name        string
+		size        int
+		step        int
+		expectItems []any
+	}{
+		{
+			name: "overlapping windows",
+			size: 3,
+			step: 1,
+			expectItems: []any{
+				[]any{int64(0), int64(1), int64(2)},
+				[]any{int64(1), int64(2), int64(3)},
+				[]any{int64(2), int64(3), int64(4)},
+			},
+		},
+		{
+			name: "overlapping windows with a new item in the last window",
+			size: 3,
+			step: 2,
+			expectItems: []any{
+				[]any{int64(0), int64(1), int64(2)},
+				[]any{int64(2), int64(3), int64(4)},
+			},
+		},
+		{
+			name: "skipping windows",
+			size: 2,
+			step: 3,
+			expectItems: []any{
+				[]any{int64(0), int64(1)},
+				[]any{int64(3), int64(4)},
+			},
+		},
+		{
+			name: "window larger than the stream",
+			size: 10,
+			step: 1,
+			expectItems: []any{
+				[]any{int64(0), int64(1), int64(2), int64(3), int64(4)},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expectItems, Range[int64](0, 5).SlidingWindow(test.size, test.step).ToIfaceSlice())
+		})
+	}
+}
+
+func TestConcurrentStream_TimeWindow(t *testing.T) {
+	s := From(func(source chan<- any) {
+		for i := 0; i < 5; i++ {
+			source <- i
+		}
+		time.Sleep(100 * time.Millisecond)
+		source <- 5
+	})
+	require.Equal(t, []any{
+		[]any{0, 1, 2},
+		[]any{3, 4},
+		[]any{5},
+	}, s.TimeWindow(3, 20*time.Millisecond).ToIfaceSlice())
+}
+
+func TestConcurrentStream_SessionWindow(t *testing.T) {
+	s := From(func(source chan<- any) {
+		for i := 0; i < 6; i++ {
+			if i == 3 {
+				time.Sleep(100 * time.Millisecond)
+			}
+			source <- i
+		}
+	})
+	require.Equal(t, []any{
+		[]any{0, 1, 2},
+		[]any{3, 4, 5},
+	}, s.SessionWindow(20*time.Millisecond).ToIfaceSlice())
+}
+
 // Shuffle shuffles the slice in place.
 // See https://en.wikipedia.org/wiki/Fisher%E2%80%93Yates_shuffle.
 func Shuffle[T any](s []T) {
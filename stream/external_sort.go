@@ -0,0 +1,334 @@
+package stream
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// defaultSortMaxItems is the default number of items SortExternal holds in memory
+const defaultSortMaxItems = 100000
+
+// Codec encodes and decodes the items spilled to disk by SortExternal
+type Codec interface {
+	// NewEncoder returns an encoder that writes items to w
+	NewEncoder(w io.Writer) Encoder
+	// NewDecoder returns a decoder that reads items from r
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Encoder writes items to the underlying writer
+type Encoder interface {
+	Encode(item any) error
+}
+
+// Decoder reads items from the underlying reader,
+// Decode returns io.EOF when there are no more items.
+type Decoder interface {
+	Decode() (item any, err error)
+}
+
+type gobCodec[T any] struct{}
+
+// NewGobCodec returns a Codec that encodes items of type T by encoding/gob
+func NewGobCodec[T any]() Codec {
+	return gobCodec[T]{}
+}
+
+func (gobCodec[T]) NewEncoder(w io.Writer) Encoder {
+	return typedEncoder[T]{enc: gob.NewEncoder(w)}
+}
+
+func (gobCodec[T]) NewDecoder(r io.Reader) Decoder {
+	return typedDecoder[T]{dec: gob.NewDecoder(r)}
+}
+
+type jsonCodec[T any] struct{}
+
+// NewJSONCodec returns a Codec that encodes items of type T by encoding/json
+func NewJSONCodec[T any]() Codec {
+	return jsonCodec[T]{}
+}
+
+func (jsonCodec[T]) NewEncoder(w io.Writer) Encoder {
+	return typedEncoder[T]{enc: json.NewEncoder(w)}
+}
+
+func (jsonCodec[T]) NewDecoder(r io.Reader) Decoder {
+	return typedDecoder[T]{dec: json.NewDecoder(r)}
+}
+
+type typedEncoder[T any] struct {
+	enc interface{ Encode(v any) error }
+}
+
+func (te typedEncoder[T]) Encode(item any) error {
+	return te.enc.Encode(cast[T](item))
+}
+
+type typedDecoder[T any] struct {
+	dec interface{ Decode(v any) error }
+}
+
+func (td typedDecoder[T]) Decode() (item any, err error) {
+	var t T
+	if err = td.dec.Decode(&t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// WithMaxItemsInMemory returns an option that sets the maximum number of items SortExternal holds in memory,
+// the default limit is 100000. The limit counts items rather than bytes, so it should be lower for large items.
+func WithMaxItemsInMemory(limit int) Option {
+	if limit <= 0 {
+		panic("max items in memory must be greater than 0")
+	}
+	return func(s Stream) {
+		if cs, ok := s.(*concurrentStream); ok {
+			cs.sortMaxItems = limit
+		} else {
+			panic("stream: WithMaxItemsInMemory must be used with concurrentStream")
+		}
+	}
+}
+
+// WithTempDir returns an option that sets the directory of the temporary files of SortExternal,
+// the default directory is os.TempDir().
+func WithTempDir(dir string) Option {
+	return func(s Stream) {
+		if cs, ok := s.(*concurrentStream); ok {
+			cs.sortTempDir = dir
+		} else {
+			panic("stream: WithTempDir must be used with concurrentStream")
+		}
+	}
+}
+
+func (cs *concurrentStream) SortExternal(less LessFunc, codec Codec, opts ...Option) Stream {
+	cs.applyOptions(opts...)
+	// the runs are spilled in a single goroutine, the sorted stream keeps the parallelism
+	parallelism := cs.parallelism
+	limit := cs.sortMaxItems
+	if limit <= 0 {
+		limit = defaultSortMaxItems
+	}
+
+	var dir string
+	// merging is true once the merging routine takes over the temporary directory
+	var merging bool
+	defer func() {
+		if dir != "" && !merging {
+			_ = os.RemoveAll(dir)
+		}
+	}()
+	var runs []string
+	var err error
+	run := make([]any, 0, limit)
+	cs.ForEach(func(item any) {
+		if err != nil {
+			return
+		}
+		run = append(run, item)
+		if len(run) < limit {
+			return
+		}
+
+		if dir == "" {
+			if dir, err = os.MkdirTemp(cs.sortTempDir, "stream-sort-"); err != nil {
+				cs.pipeline.fail(err)
+				return
+			}
+		}
+		var path string
+		if path, err = spillRun(dir, len(runs), run, less, codec); err != nil {
+			cs.pipeline.fail(err)
+			return
+		}
+		runs = append(runs, path)
+		run = run[:0]
+	}, WithSync())
+	cs.parallelism = parallelism
+
+	if err != nil {
+		// the error has been recorded into the pipeline and inherited by the new one
+		return cs.newStreamWithSlice(nil)
+	}
+	sortRun(run, less)
+	if len(runs) == 0 {
+		return cs.newStreamWithSlice(run)
+	}
+
+	ns := cs.fork()
+	out := make(chan any, cs.parallelism)
+	merging = true
+	go func() {
+		defer close(out)
+		defer func() { _ = os.RemoveAll(dir) }()
+		defer func() { ns.pipeline.handlePanic(recover(), nil) }()
+
+		if err := mergeRuns(ns.ctx, runs, run, less, codec, func(item any) bool {
+			return ns.send(out, item)
+		}); err != nil {
+			ns.pipeline.fail(err)
+		}
+	}()
+	ns.source = out
+	return ns
+}
+
+// sortRun sorts the run by less, the equal items keep their encounter order
+func sortRun(run []any, less LessFunc) {
+	sort.SliceStable(run, func(i, j int) bool {
+		return less(run[i], run[j])
+	})
+}
+
+// spillRun sorts the run and writes it to a new file in dir
+func spillRun(dir string, index int, run []any, less LessFunc, codec Codec) (path string, err error) {
+	sortRun(run, less)
+
+	path = filepath.Join(dir, fmt.Sprintf("run-%d", index))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	enc := codec.NewEncoder(w)
+	for _, item := range run {
+		if err = enc.Encode(item); err != nil {
+			return "", err
+		}
+	}
+	return path, w.Flush()
+}
+
+// mergeRuns merges the sorted runs on disk and the sorted run in memory,
+// and emits the items in order until emit returns false.
+func mergeRuns(
+	ctx context.Context,
+	paths []string,
+	memoryRun []any,
+	less LessFunc,
+	codec Codec,
+	emit func(item any) bool,
+) error {
+	h := &runHeap{less: less}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r := &runReader{index: h.Len(), dec: codec.NewDecoder(bufio.NewReader(f))}
+		if err = h.pushNext(r); err != nil {
+			return err
+		}
+	}
+	if err := h.pushNext(&runReader{index: h.Len(), memory: memoryRun}); err != nil {
+		return err
+	}
+
+	for h.Len() > 0 {
+		if ctx.Err() != nil {
+			return nil
+		}
+		r := h.readers[0]
+		if !emit(r.head) {
+			return nil
+		}
+		heap.Pop(h)
+		if err := h.pushNext(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runReader reads the items of a sorted run, from a file or from memory
+type runReader struct {
+	index  int
+	dec    Decoder
+	memory []any
+	head   any
+}
+
+// next reads the next item of the run into head, ok is false if the run is exhausted
+func (r *runReader) next() (ok bool, err error) {
+	if r.dec == nil {
+		if len(r.memory) == 0 {
+			return false, nil
+		}
+		r.head, r.memory = r.memory[0], r.memory[1:]
+		return true, nil
+	}
+
+	r.head, err = r.dec.Decode()
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// runHeap is a min-heap of run readers ordered by their heads,
+// the readers with equal heads are ordered by their index, so that the merge is stable.
+type runHeap struct {
+	readers []*runReader
+	less    LessFunc
+}
+
+func (h *runHeap) Len() int {
+	return len(h.readers)
+}
+
+func (h *runHeap) Less(i, j int) bool {
+	a, b := h.readers[i], h.readers[j]
+	if h.less(a.head, b.head) {
+		return true
+	}
+	if h.less(b.head, a.head) {
+		return false
+	}
+	return a.index < b.index
+}
+
+func (h *runHeap) Swap(i, j int) {
+	h.readers[i], h.readers[j] = h.readers[j], h.readers[i]
+}
+
+func (h *runHeap) Push(x any) {
+	h.readers = append(h.readers, x.(*runReader))
+}
+
+func (h *runHeap) Pop() any {
+	last := h.readers[len(h.readers)-1]
+	h.readers = h.readers[:len(h.readers)-1]
+	return last
+}
+
+// pushNext reads the next item of the reader, and pushes the reader back to the heap if it is not exhausted
+func (h *runHeap) pushNext(r *runReader) error {
+	ok, err := r.next()
+	if err != nil {
+		return err
+	}
+	if ok {
+		heap.Push(h, r)
+	}
+	return nil
+}
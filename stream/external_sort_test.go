@@ -0,0 +1,102 @@
+package stream
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"os"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestConcurrentStream_SortExternal(t *testing.T) {
+	type person struct {
+		Name string
+		Age  int
+	}
+
+	items := make([]person, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		items = append(items, person{Name: string(rune('a' + i%26)), Age: rand.Intn(100)})
+	}
+	expectedItems := make([]person, len(items))
+	copy(expectedItems, items)
+	sort.SliceStable(expectedItems, func(i, j int) bool {
+		return expectedItems[i].Age < expectedItems[j].Age
+	})
+
+	tests := []struct {
+		name        string
+		codec       Codec
+		memoryLimit int
+		parallelism uint
+	}{
+		{name: "gob", codec: NewGobCodec[person](), memoryLimit: 64, parallelism: 1},
+		{name: "json", codec: NewJSONCodec[person](), memoryLimit: 100, parallelism: 4},
+		{name: "in memory", codec: NewGobCodec[person](), memoryLimit: 2000, parallelism: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			actualItems := Of(items, WithParallelism(test.parallelism)).SortExternal(func(a, b person) bool {
+				return a.Age < b.Age
+			}, test.codec, WithMaxItemsInMemory(test.memoryLimit), WithTempDir(dir)).ToSlice()
+			require.Equal(t, expectedItems, actualItems)
+			requireEmptyDir(t, dir)
+		})
+	}
+
+	// the sorted stream keeps the parallelism
+	sorted := Range[int64](0, 100, WithParallelism(4)).SortExternal(func(a, b any) bool {
+		return a.(int64) > b.(int64)
+	}, NewGobCodec[int64](), WithMaxItemsInMemory(10), WithTempDir(t.TempDir()))
+	require.Equal(t, uint(4), sorted.(*concurrentStream).parallelism)
+	require.Equal(t, int64(99), sorted.Map(func(item any) any {
+		return item
+	}, WithOrdered()).ToIfaceSlice()[0])
+}
+
+func TestConcurrentStream_SortExternalStopped(t *testing.T) {
+	less := func(a, b any) bool {
+		return a.(int) > b.(int)
+	}
+
+	dir := t.TempDir()
+	item, found := Range(0, 1000).SortExternal(less, NewGobCodec[int](), WithMaxItemsInMemory(10), WithTempDir(dir)).FindFirst()
+	require.True(t, found)
+	require.Equal(t, 999, item)
+	requireEmptyDir(t, dir)
+
+	dir = t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := Range(0, 1000, WithContext(ctx)).
+		SortExternal(less, NewGobCodec[int](), WithMaxItemsInMemory(10), WithTempDir(dir))
+	cancel()
+	s.ForEach(func(item any) {})
+	require.ErrorIs(t, s.Err(), context.Canceled)
+	requireEmptyDir(t, dir)
+
+	// the runs spilled before a panic of less are removed
+	dir = t.TempDir()
+	calls := 0
+	panicking := func(a, b any) bool {
+		if calls++; calls > 100 {
+			panic("boom")
+		}
+		return less(a, b)
+	}
+	require.Panics(t, func() {
+		Range(0, 1000).SortExternal(panicking, NewGobCodec[int](), WithMaxItemsInMemory(10), WithTempDir(dir)).ToIfaceSlice()
+	})
+	requireEmptyDir(t, dir)
+}
+
+// requireEmptyDir requires the temporary files in dir to be removed, they are removed asynchronously
+func requireEmptyDir(t *testing.T, dir string) {
+	require.Eventually(t, func() bool {
+		entries, err := os.ReadDir(dir)
+		return err == nil && len(entries) == 0
+	}, time.Second, 10*time.Millisecond)
+}
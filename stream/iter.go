@@ -0,0 +1,33 @@
+//go:build go1.23
+
+package stream
+
+import "iter"
+
+// All returns an iter.Seq over the items of the stream, the stream is stopped when the loop breaks.
+func All(s Stream) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		it := s.Iterator()
+		defer it.Stop()
+
+		for item, ok := it.Next(); ok; item, ok = it.Next() {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iter.Seq over the items of the stream, the stream is stopped when the loop breaks.
+func (ts TypedStream[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		it := ts.Iterator()
+		defer it.Stop()
+
+		for item, ok := it.Next(); ok; item, ok = it.Next() {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,25 @@
+//go:build go1.23
+
+package stream
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	var items []any
+	for item := range All(Range(0, 1000)) {
+		if item.(int) == 3 {
+			break
+		}
+		items = append(items, item)
+	}
+	require.Equal(t, []any{0, 1, 2}, items)
+
+	var typed []string
+	for item := range Of([]string{"a", "b"}).All() {
+		typed = append(typed, item)
+	}
+	require.Equal(t, []string{"a", "b"}, typed)
+}
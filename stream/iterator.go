@@ -0,0 +1,82 @@
+package stream
+
+import "context"
+
+// Iterator pulls the items of a stream one by one
+type Iterator[E any] interface {
+	// Next returns the next item of the stream.
+	// Returns false if the stream ends.
+	Next() (item E, ok bool)
+	// Stop stops the stream, it is unnecessary once Next returns false.
+	Stop()
+}
+
+// FromIterator returns a stream of the items returned by the given iterator, such as collection.Iterator.
+//
+// The iterator is called in a single goroutine until it returns false or the stream is stopped.
+func FromIterator[E any](it interface{ Next() (E, bool) }, opts ...Option) Stream {
+	return FromContext(func(ctx context.Context, source chan<- any) {
+		for item, ok := it.Next(); ok; item, ok = it.Next() {
+			select {
+			case source <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}, opts...)
+}
+
+// OfIterator returns a typed stream of the items returned by the given iterator, see FromIterator.
+func OfIterator[T any](it interface{ Next() (T, bool) }, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: FromIterator[T](it, opts...)}
+}
+
+func (cs *concurrentStream) Iterator(opts ...Option) Iterator[any] {
+	cs.applyOptions(opts...)
+	return &streamIterator{cs: cs}
+}
+
+// streamIterator receives the items of the stream in the calling goroutine,
+// the stream is finished when it ends or is stopped.
+type streamIterator struct {
+	cs       *concurrentStream
+	finished bool
+}
+
+func (it *streamIterator) Next() (item any, ok bool) {
+	if it.finished {
+		return nil, false
+	}
+	if item, ok = it.cs.receive(); !ok {
+		it.Stop()
+	}
+	return item, ok
+}
+
+func (it *streamIterator) Stop() {
+	if !it.finished {
+		it.finished = true
+		it.cs.finish()
+	}
+}
+
+// Iterator returns an iterator which pulls the items of the stream one by one, see Stream.Iterator.
+func (ts TypedStream[T]) Iterator(opts ...Option) Iterator[T] {
+	return typedIterator[T]{it: ts.s.Iterator(opts...)}
+}
+
+type typedIterator[T any] struct {
+	it Iterator[any]
+}
+
+func (it typedIterator[T]) Next() (item T, ok bool) {
+	iface, ok := it.it.Next()
+	if !ok {
+		return item, false
+	}
+	return cast[T](iface), true
+}
+
+func (it typedIterator[T]) Stop() {
+	it.it.Stop()
+}
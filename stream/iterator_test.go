@@ -0,0 +1,84 @@
+package stream
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type sliceIterator struct {
+	items []int
+}
+
+func (it *sliceIterator) Next() (int, bool) {
+	if len(it.items) == 0 {
+		return 0, false
+	}
+	item := it.items[0]
+	it.items = it.items[1:]
+	return item, true
+}
+
+func TestFromIterator(t *testing.T) {
+	require.Equal(t, []any{1, 2, 3}, FromIterator[int](&sliceIterator{items: []int{1, 2, 3}}).ToIfaceSlice())
+	require.Equal(t, []int{2, 3}, OfIterator[int](&sliceIterator{items: []int{1, 2, 3}}).Skip(1).ToSlice())
+}
+
+func TestConcurrentStream_Iterator(t *testing.T) {
+	it := Range(0, 3).Map(func(item any) any {
+		return item.(int) * 2
+	}).Iterator()
+	var items []any
+	for item, ok := it.Next(); ok; item, ok = it.Next() {
+		items = append(items, item)
+	}
+	require.Equal(t, []any{0, 2, 4}, items)
+
+	// interleave two streams step by step
+	a, b := Of([]int{1, 3, 5}).Iterator(), Of([]int{2, 4}).Iterator()
+	var merged []int
+	for {
+		itemA, okA := a.Next()
+		if okA {
+			merged = append(merged, itemA)
+		}
+		itemB, okB := b.Next()
+		if okB {
+			merged = append(merged, itemB)
+		}
+		if !okA && !okB {
+			break
+		}
+	}
+	require.Equal(t, []int{1, 2, 3, 4, 5}, merged)
+}
+
+func TestConcurrentStream_IteratorStop(t *testing.T) {
+	generatorDone := make(chan struct{})
+	s := FromContext(func(ctx context.Context, source chan<- any) {
+		defer close(generatorDone)
+		for i := 0; ; i++ {
+			select {
+			case source <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	it := s.Iterator()
+	item, ok := it.Next()
+	require.True(t, ok)
+	require.Equal(t, 0, item)
+	it.Stop()
+
+	_, ok = it.Next()
+	require.False(t, ok)
+	require.NoError(t, s.Err())
+	select {
+	case <-generatorDone:
+	case <-time.After(time.Second):
+		require.Fail(t, "the stream is not stopped")
+	}
+}
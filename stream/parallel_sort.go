@@ -0,0 +1,120 @@
+package stream
+
+import (
+	"sort"
+	"sync"
+)
+
+// minParallelSortChunk is the minimum number of items of a chunk sorted in its own goroutine
+const minParallelSortChunk = 1024
+
+// parallelSort sorts items by less in at most parallelism goroutines.
+//
+// The items are split into chunks which are sorted concurrently, then the adjacent chunks are merged pairwise
+// until one is left. If stable is true, the equal items keep their original order.
+// A panic of less is re-panicked on the calling goroutine.
+func parallelSort(items []any, less LessFunc, parallelism uint, stable bool) {
+	sortChunk := func(chunk []any) {
+		lessIndex := func(i, j int) bool {
+			return less(chunk[i], chunk[j])
+		}
+		if stable {
+			sort.SliceStable(chunk, lessIndex)
+		} else {
+			sort.Slice(chunk, lessIndex)
+		}
+	}
+
+	chunks := int(parallelism)
+	if maxChunks := len(items) / minParallelSortChunk; chunks > maxChunks {
+		chunks = maxChunks
+	}
+	if chunks <= 1 {
+		sortChunk(items)
+		return
+	}
+
+	// bounds[i] and bounds[i+1] are the start and the end of the i-th chunk
+	bounds := make([]int, 0, chunks+1)
+	for i := 0; i <= chunks; i++ {
+		bounds = append(bounds, i*len(items)/chunks)
+	}
+
+	var panicOnce sync.Once
+	var panicValue any
+	run := func(wg *sync.WaitGroup, task func()) {
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					panicOnce.Do(func() {
+						panicValue = r
+					})
+				}
+			}()
+			task()
+		}()
+	}
+
+	wg := new(sync.WaitGroup)
+	wg.Add(chunks)
+	for i := 0; i < chunks; i++ {
+		chunk := items[bounds[i]:bounds[i+1]]
+		run(wg, func() {
+			sortChunk(chunk)
+		})
+	}
+	wg.Wait()
+	if panicValue != nil {
+		panic(panicValue)
+	}
+
+	src, dst := items, make([]any, len(items))
+	for len(bounds) > 2 {
+		merged := make([]int, 0, len(bounds)/2+1)
+		wg = new(sync.WaitGroup)
+		for i := 0; i+1 < len(bounds); i += 2 {
+			merged = append(merged, bounds[i])
+			if i+2 >= len(bounds) {
+				// the last chunk has no pair, copy it as is
+				copy(dst[bounds[i]:bounds[i+1]], src[bounds[i]:bounds[i+1]])
+				continue
+			}
+
+			start, mid, end := bounds[i], bounds[i+1], bounds[i+2]
+			wg.Add(1)
+			run(wg, func() {
+				mergeSorted(dst[start:end], src[start:mid], src[mid:end], less)
+			})
+		}
+		merged = append(merged, len(items))
+		wg.Wait()
+		if panicValue != nil {
+			panic(panicValue)
+		}
+
+		src, dst = dst, src
+		bounds = merged
+	}
+	if &src[0] != &items[0] {
+		copy(items, src)
+	}
+}
+
+// mergeSorted merges the sorted left and right into dst,
+// the items of left come first if they are equal to the items of right.
+func mergeSorted(dst, left, right []any, less LessFunc) {
+	i, j, k := 0, 0, 0
+	for i < len(left) && j < len(right) {
+		if less(right[j], left[i]) {
+			dst[k] = right[j]
+			j++
+		} else {
+			dst[k] = left[i]
+			i++
+		}
+		k++
+	}
+	k += copy(dst[k:], left[i:])
+	copy(dst[k:], right[j:])
+}
@@ -0,0 +1,45 @@
+package stream
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+)
+
+func TestParallelSort(t *testing.T) {
+	type item struct {
+		key   int
+		index int
+	}
+
+	for _, size := range []int{0, 1, 100, 5000, 10007} {
+		for _, parallelism := range []uint{1, 2, 3, 4, 7} {
+			items := make([]any, 0, size)
+			for i := 0; i < size; i++ {
+				items = append(items, item{key: rand.Intn(100), index: i})
+			}
+
+			parallelSort(items, func(a, b any) bool {
+				return a.(item).key < b.(item).key
+			}, parallelism, true)
+
+			require.Len(t, items, size)
+			for i := 1; i < len(items); i++ {
+				prev, cur := items[i-1].(item), items[i].(item)
+				require.LessOrEqual(t, prev.key, cur.key)
+				if prev.key == cur.key {
+					require.Less(t, prev.index, cur.index, "equal items must keep their order")
+				}
+			}
+		}
+	}
+}
+
+func TestParallelSort_Panic(t *testing.T) {
+	items := Range(0, 10000).ToIfaceSlice()
+	require.PanicsWithValue(t, "boom", func() {
+		parallelSort(items, func(a, b any) bool {
+			panic("boom")
+		}, 4, false)
+	})
+}
@@ -0,0 +1,239 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// ErrorPolicy decides what a stream does when an error-aware operation fails
+type ErrorPolicy int
+
+const (
+	// FailFast stops the stream at the first error,
+	// the terminal operation returns the first error.
+	FailFast ErrorPolicy = iota
+	// CollectAllErrors skips the failed items and keeps going,
+	// the terminal operation returns all errors as a MultiError.
+	CollectAllErrors
+)
+
+// PanicPolicy decides what a stream does when a worker panics
+type PanicPolicy int
+
+const (
+	// RethrowPanics stops the stream at the first panic,
+	// the terminal operation panics with a *PanicError on the calling goroutine.
+	RethrowPanics PanicPolicy = iota
+	// ReturnPanics handles the panic as an error of the item by the error policy,
+	// the terminal operation returns a *PanicError, see Stream.Err.
+	ReturnPanics
+	// SkipPanics skips the panicking items and keeps going regardless of the error policy,
+	// the terminal operation returns the *PanicError of each item, see Stream.Err.
+	SkipPanics
+)
+
+// PanicError is a panic recovered from a stream worker
+type PanicError struct {
+	// Item is the item being processed, nil if the panic is not caused by an item, e.g. a generator panic.
+	Item any
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("stream: panic while processing item %v: %v\n\n%s", pe.Item, pe.Value, pe.Stack)
+}
+
+// Unwrap returns the panic value if it is an error
+func (pe *PanicError) Unwrap() error {
+	if err, ok := pe.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// MultiError is the error returned by a stream with CollectAllErrors policy
+// when more than one item failed.
+type MultiError []error
+
+func (me MultiError) Error() string {
+	sb := strings.Builder{}
+	for i, err := range me {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the errors, so that errors.Is and errors.As can inspect each of them.
+func (me MultiError) Unwrap() []error {
+	return me
+}
+
+// pipeline is the state shared by all stages of a stream,
+// from the generator to the terminal operation.
+type pipeline struct {
+	mu      sync.Mutex
+	parent  context.Context
+	cancels []context.CancelFunc
+	stopped bool
+	policy  ErrorPolicy
+	failed  bool
+	errs    []error
+
+	panicPolicy PanicPolicy
+	panicErr    *PanicError
+}
+
+func newPipeline(parent context.Context, policy ErrorPolicy) *pipeline {
+	return &pipeline{
+		parent: parent,
+		policy: policy,
+	}
+}
+
+func (p *pipeline) setParent(parent context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.parent = parent
+}
+
+func (p *pipeline) setPolicy(policy ErrorPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.policy = policy
+}
+
+func (p *pipeline) setPanicPolicy(policy PanicPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.panicPolicy = policy
+}
+
+// derive returns a new context of the parent context,
+// which is cancelled when the pipeline is stopped.
+func (p *pipeline) derive() context.Context {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(p.parent)
+	if p.stopped {
+		cancel()
+	} else {
+		p.cancels = append(p.cancels, cancel)
+	}
+	return ctx
+}
+
+// fork returns a new pipeline with the same parent context, policies and errors,
+// it is used by the operations that consume the whole upstream before emitting any item.
+func (p *pipeline) fork() *pipeline {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	np := newPipeline(p.parent, p.policy)
+	np.panicPolicy = p.panicPolicy
+	np.errs = append(np.errs, p.errs...)
+	np.failed = p.failed
+	np.stopped = p.failed
+	return np
+}
+
+// fail records the error, and stops the pipeline if the policy is FailFast
+func (p *pipeline) fail(err error) {
+	p.mu.Lock()
+	if p.failed {
+		p.mu.Unlock()
+		return
+	}
+	p.errs = append(p.errs, err)
+	p.failed = p.policy == FailFast
+	failFast := p.failed
+	p.mu.Unlock()
+
+	if failFast {
+		p.stop()
+	}
+}
+
+// handlePanic handles r, the value returned by recover(), of a worker processing the item.
+//
+// It must be called in a deferred function, e.g.
+//
+//	defer func() { p.handlePanic(recover(), item) }()
+func (p *pipeline) handlePanic(r any, item any) {
+	if r == nil {
+		return
+	}
+	pe, ok := r.(*PanicError)
+	if !ok {
+		pe = &PanicError{Item: item, Value: r, Stack: debug.Stack()}
+	}
+
+	p.mu.Lock()
+	switch p.panicPolicy {
+	case ReturnPanics:
+		p.mu.Unlock()
+		p.fail(pe)
+	case SkipPanics:
+		p.errs = append(p.errs, pe)
+		p.mu.Unlock()
+	default:
+		if p.panicErr == nil {
+			p.panicErr = pe
+		}
+		p.mu.Unlock()
+		p.stop()
+	}
+}
+
+// rethrow panics with the recovered panic if the panic policy is RethrowPanics
+func (p *pipeline) rethrow() {
+	p.mu.Lock()
+	pe := p.panicErr
+	p.mu.Unlock()
+
+	if pe != nil {
+		panic(pe)
+	}
+}
+
+// err returns the recorded errors, nil if there is no error
+func (p *pipeline) err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch len(p.errs) {
+	case 0:
+		return nil
+	case 1:
+		return p.errs[0]
+	default:
+		errs := make(MultiError, len(p.errs))
+		copy(errs, p.errs)
+		return errs
+	}
+}
+
+// stop cancels all contexts derived from the pipeline
+func (p *pipeline) stop() {
+	p.mu.Lock()
+	cancels := p.cancels
+	p.cancels = nil
+	p.stopped = true
+	p.mu.Unlock()
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
@@ -1,6 +1,10 @@
 package stream
 
 import (
+	"context"
+	"runtime/debug"
+	"time"
+
 	"golang.org/x/exp/constraints"
 )
 
@@ -8,6 +12,9 @@ type (
 	// source is the channel that the generator function writes to,
 	// and the generator should not close the channel
 	GenerateFunc func(source chan<- any)
+	// GenerateContextFunc is like GenerateFunc, but the generator should return
+	// as soon as ctx is done instead of blocking on the source channel.
+	GenerateContextFunc func(ctx context.Context, source chan<- any)
 
 	MapFunc         func(item any) any
 	FlatMapFunc     func(item any) Stream
@@ -19,6 +26,12 @@ type (
 	ConsumeFunc     func(item any)
 	SupplierFunc    func() any
 
+	MapEFunc         func(item any) (any, error)
+	FlatMapEFunc     func(item any) (Stream, error)
+	FilterEFunc      func(item any) (bool, error)
+	AccumulatorEFunc func(identity any, item any) (any, error)
+	ConsumeEFunc     func(item any) error
+
 	Option func(s Stream)
 )
 
@@ -26,16 +39,37 @@ type (
 type Stream interface {
 	// Map applies the given mapper to each item in the stream
 	Map(mapper MapFunc, opts ...Option) Stream
+	// MapE is like Map, but the mapper can fail.
+	// The failed item is handled by the error policy of the stream, see WithErrorPolicy.
+	MapE(mapper MapEFunc, opts ...Option) Stream
 	// FlatMap applies the given mapper to each item in the stream
 	FlatMap(mapper FlatMapFunc, opts ...Option) Stream
+	// FlatMapE is like FlatMap, but the mapper can fail.
+	// The failed item is handled by the error policy of the stream, see WithErrorPolicy.
+	FlatMapE(mapper FlatMapEFunc, opts ...Option) Stream
 	// Filter filters the stream by the given predicate
 	Filter(filter FilterFunc, opts ...Option) Stream
+	// FilterE is like Filter, but the predicate can fail.
+	// The failed item is handled by the error policy of the stream, see WithErrorPolicy.
+	FilterE(filter FilterEFunc, opts ...Option) Stream
 	// Concat concatenates the given streams to the current stream
 	Concat(streams []Stream, opts ...Option) Stream
-	// Sort sorts the stream by the given less function
+	// Sort sorts the stream by the given less function.
+	// If the stream is parallel, the items are sorted by a parallel merge sort.
 	Sort(less LessFunc, opts ...Option) Stream
+	// SortStable is like Sort, but the equal items keep their encounter order.
+	SortStable(less LessFunc, opts ...Option) Stream
+	// SortExternal sorts the stream by the given less function without holding all items in memory.
+	// The items are sorted in runs of at most the max items in memory, the runs are spilled to temporary files
+	// by the given codec and merged back lazily, the temporary files are removed when the returned stream
+	// ends or is stopped. See WithMaxItemsInMemory and WithTempDir.
+	SortExternal(less LessFunc, codec Codec, opts ...Option) Stream
 	// Distinct removes the duplicate items in the stream
 	Distinct(distinct DistinctFunc, opts ...Option) Stream
+	// DistinctApprox is like Distinct, but the keys are kept in a scalable Bloom filter instead of a map,
+	// so the memory grows by a few bits per key.
+	// An item may be removed by mistake with the false positive rate fpRate, but a duplicate item is never kept.
+	DistinctApprox(distinct DistinctFunc, fpRate float64, opts ...Option) Stream
 	// Skip skips the first n items in the stream
 	Skip(limit int64, opts ...Option) Stream
 	// Limit limits the number of items in the stream
@@ -50,6 +84,19 @@ type Stream interface {
 	DropWhile(match MatchFunc, opts ...Option) Stream
 	// Peek applies the given consumer to each item in the stream
 	Peek(consumer ConsumeFunc, opts ...Option) Stream
+	// Window groups the items into windows of the given size, each window is emitted as []any.
+	// The last window may be smaller than the given size.
+	Window(size int, opts ...Option) Stream
+	// SlidingWindow emits a window of the given size every step items, each window is emitted as []any.
+	// The windows overlap if step < size, and some items are skipped if step > size.
+	// The last window may be smaller than the given size, it is emitted only if it contains new items.
+	SlidingWindow(size, step int, opts ...Option) Stream
+	// TimeWindow emits a window when it reaches the given size,
+	// or the given duration has elapsed since its first item, each window is emitted as []any.
+	TimeWindow(size int, duration time.Duration, opts ...Option) Stream
+	// SessionWindow emits a window when no item arrives within the given gap since its last item,
+	// each window is emitted as []any.
+	SessionWindow(gap time.Duration, opts ...Option) Stream
 
 	// AnyMatch returns true if any item in the stream matches the given predicate, otherwise false.
 	// If the stream is empty, false is returned.
@@ -78,8 +125,14 @@ type Stream interface {
 	Count(opts ...Option) int64
 	// Reduce reduces the stream to a single value by the given accumulator function.
 	Reduce(identity any, accumulator AccumulatorFunc, opts ...Option) any
+	// ReduceE is like Reduce, but the accumulator can fail.
+	// It returns the error of the stream, see Err.
+	ReduceE(identity any, accumulator AccumulatorEFunc, opts ...Option) (any, error)
 	// ForEach applies the given consumer to each item in the stream
 	ForEach(consumer ConsumeFunc, opts ...Option)
+	// ForEachE is like ForEach, but the consumer can fail.
+	// It returns the error of the stream, see Err.
+	ForEachE(consumer ConsumeEFunc, opts ...Option) error
 	// ToIfaceSlice returns the stream as a slice of interface{}
 	ToIfaceSlice(opts ...Option) []any
 	// ApplyOptions applies the given options to the stream
@@ -100,28 +153,113 @@ type Stream interface {
 	// - NewMaxCollector
 	// - NewMinCollector
 	//
+	// If the stream is parallel, the collectors above which are collector.ParallelCollector,
+	// such as NewSumCollector, accumulate into a container per worker, see CollectWithCombiner.
+	// Other accumulators must be routine-safe.
+	//
 	// Please refer to the example in collectors_test.go for more details.
 	Collect(
 		supplier func() any,
 		accumulator func(container, item any),
 		finisher func(container any) any,
 	) any
+	// CollectWithCombiner is like Collect, but each worker of a parallel stream accumulates into its own container,
+	// and the containers are merged by the combiner before the finisher is applied.
+	// So the accumulator does not need to be routine-safe.
+	CollectWithCombiner(
+		supplier func() any,
+		accumulator func(container, item any),
+		combiner func(a, b any) any,
+		finisher func(container any) any,
+	) any
+	// CollectE is like Collect, but the accumulator can fail.
+	// It returns the error of the stream, see Err.
+	CollectE(
+		supplier func() any,
+		accumulator func(container, item any) error,
+		finisher func(container any) any,
+	) (any, error)
+	// Iterator returns an iterator which pulls the items of the stream one by one in the calling goroutine.
+	// Call Iterator.Stop to stop the stream if the iterator is not exhausted.
+	Iterator(opts ...Option) Iterator[any]
 	// Close closes the stream
 	Close()
+	// Err returns the error that stopped the stream, if any.
+	//
+	// If the context given by WithContext is done before a terminal operation finishes,
+	// the terminal operation returns early and Err returns ctx.Err().
+	//
+	// If an error-aware operation such as MapE fails, Err returns the error,
+	// or a MultiError of all errors if the error policy is CollectAllErrors.
+	//
+	// A panic of a worker is re-panicked as a *PanicError by the terminal operation,
+	// or reported by Err if the panic policy is ReturnPanics or SkipPanics, see WithPanicPolicy.
+	Err() error
 }
 
 // From returns a stream from the given generator function
+//
+// The generator is not aware of the stream context, use FromContext for
+// infinite or expensive generators so that they can stop when the stream is cancelled.
 func From(generator GenerateFunc, opts ...Option) Stream {
-	source := make(chan any)
-	go func() {
-		defer close(source)
-		generator(source)
-	}()
+	return FromContext(func(ctx context.Context, source chan<- any) {
+		items := make(chan any)
+		var pe *PanicError
+		go func() {
+			defer close(items)
+			defer func() {
+				if r := recover(); r != nil {
+					pe = &PanicError{Value: r, Stack: debug.Stack()}
+				}
+			}()
+
+			generator(items)
+		}()
+
+		for item := range items {
+			select {
+			case source <- item:
+			case <-ctx.Done():
+				// the generator can only stop by running out of items
+				go drain(items)
+				return
+			}
+		}
+		if pe != nil {
+			// re-panic in the goroutine of FromContext, so that it is handled by the panic policy
+			panic(pe)
+		}
+	}, opts...)
+}
+
+// FromContext returns a stream from the given context-aware generator function
+//
+// ctx is the context given by WithContext, or context.Background() if not given.
+func FromContext(generator GenerateContextFunc, opts ...Option) Stream {
+	return generate(func(ctx context.Context, _ *pipeline, source chan<- any) {
+		generator(ctx, source)
+	}, opts...)
+}
+
+// generate returns a stream whose items are generated by the given function in a new goroutine.
+//
+// p is the pipeline of the returned stream, the generator can record errors into it.
+func generate(generator func(ctx context.Context, p *pipeline, source chan<- any), opts ...Option) *concurrentStream {
 	cs := &concurrentStream{
-		source:      source,
 		parallelism: 1,
+		pipeline:    newPipeline(context.Background(), FailFast),
 	}
+	cs.ctx = cs.pipeline.derive()
 	cs.applyOptions(opts...)
+
+	source := make(chan any)
+	go func(ctx context.Context, p *pipeline) {
+		defer close(source)
+		defer func() { p.handlePanic(recover(), nil) }()
+
+		generator(ctx, p, source)
+	}(cs.ctx, cs.pipeline)
+	cs.source = source
 	return cs
 }
 
@@ -131,29 +269,34 @@ func From(generator GenerateFunc, opts ...Option) Stream {
 //
 // endExclusive indicates whether end is excluded in the stream
 func Range[T constraints.Integer](startInclude, endExclusive T, opts ...Option) Stream {
-	return From(func(source chan<- any) {
+	return FromContext(func(ctx context.Context, source chan<- any) {
 		for i := startInclude; i < endExclusive; i++ {
-			source <- i
+			select {
+			case source <- i:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}, opts...)
 }
 
 // Just returns a stream of the given items
 func Just[T any](items []T, opts ...Option) Stream {
-	source := make(chan any)
-	go func() {
-		defer close(source)
-
+	return FromContext(func(ctx context.Context, source chan<- any) {
 		for _, item := range items {
-			source <- item
+			select {
+			case source <- item:
+			case <-ctx.Done():
+				return
+			}
 		}
-	}()
-	cs := &concurrentStream{
-		source:      source,
-		parallelism: 1,
+	}, opts...)
+}
+
+// drain the channel
+func drain(ch <-chan any) {
+	for range ch {
 	}
-	cs.applyOptions(opts...)
-	return cs
 }
 
 // Concat concatenates the given streams to a single stream
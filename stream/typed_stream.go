@@ -0,0 +1,302 @@
+package stream
+
+import (
+	"github.com/carter-ya/go-tools/stream/collector"
+	"time"
+)
+
+// TypedStream is a type-safe view of Stream whose items are all of type T.
+//
+// It shares the implementation and the options of Stream,
+// the operations that change the item type are package-level functions, such as Map and Collect.
+type TypedStream[T any] struct {
+	s Stream
+}
+
+// Of returns a typed stream of the given items
+func Of[T any](items []T, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: Just(items, opts...)}
+}
+
+// OfStream returns a typed view of the given stream.
+//
+// Note: All items of the stream must be of type T, otherwise the operations of the typed stream will panic.
+func OfStream[T any](s Stream, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: s.ApplyOptions(opts...)}
+}
+
+// Untyped returns the stream as an untyped Stream
+func (ts TypedStream[T]) Untyped() Stream {
+	return ts.s
+}
+
+// Filter filters the stream by the given predicate
+func (ts TypedStream[T]) Filter(filter func(item T) bool, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.Filter(func(item any) bool {
+		return filter(cast[T](item))
+	}, opts...)}
+}
+
+// FilterE is like Filter, but the predicate can fail.
+// The failed item is handled by the error policy of the stream, see WithErrorPolicy.
+func (ts TypedStream[T]) FilterE(filter func(item T) (bool, error), opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.FilterE(func(item any) (bool, error) {
+		return filter(cast[T](item))
+	}, opts...)}
+}
+
+// Concat concatenates the given streams to the current stream
+func (ts TypedStream[T]) Concat(streams []TypedStream[T], opts ...Option) TypedStream[T] {
+	untyped := make([]Stream, 0, len(streams))
+	for _, s := range streams {
+		untyped = append(untyped, s.s)
+	}
+	return TypedStream[T]{s: ts.s.Concat(untyped, opts...)}
+}
+
+// Sort sorts the stream by the given less function
+func (ts TypedStream[T]) Sort(less func(a, b T) bool, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.Sort(func(a, b any) bool {
+		return less(cast[T](a), cast[T](b))
+	}, opts...)}
+}
+
+// SortStable is like Sort, but the equal items keep their encounter order
+func (ts TypedStream[T]) SortStable(less func(a, b T) bool, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.SortStable(func(a, b any) bool {
+		return less(cast[T](a), cast[T](b))
+	}, opts...)}
+}
+
+// SortExternal sorts the stream by the given less function without holding all items in memory,
+// see Stream.SortExternal.
+func (ts TypedStream[T]) SortExternal(less func(a, b T) bool, codec Codec, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.SortExternal(func(a, b any) bool {
+		return less(cast[T](a), cast[T](b))
+	}, codec, opts...)}
+}
+
+// Distinct removes the duplicate items in the stream, the items are compared by the key returned by distinct
+func (ts TypedStream[T]) Distinct(distinct func(item T) any, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.Distinct(func(item any) any {
+		return distinct(cast[T](item))
+	}, opts...)}
+}
+
+// DistinctApprox is like Distinct, but the keys are kept in a scalable Bloom filter, see Stream.DistinctApprox.
+func (ts TypedStream[T]) DistinctApprox(distinct func(item T) any, fpRate float64, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.DistinctApprox(func(item any) any {
+		return distinct(cast[T](item))
+	}, fpRate, opts...)}
+}
+
+// Skip skips the first n items in the stream
+func (ts TypedStream[T]) Skip(limit int64, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.Skip(limit, opts...)}
+}
+
+// Limit limits the number of items in the stream
+func (ts TypedStream[T]) Limit(limit int64, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.Limit(limit, opts...)}
+}
+
+// TakeWhile takes items from the stream while the given predicate is true.
+func (ts TypedStream[T]) TakeWhile(match func(item T) bool, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.TakeWhile(func(item any) bool {
+		return match(cast[T](item))
+	}, opts...)}
+}
+
+// DropWhile drops items from the stream while the given predicate is true.
+func (ts TypedStream[T]) DropWhile(match func(item T) bool, opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.DropWhile(func(item any) bool {
+		return match(cast[T](item))
+	}, opts...)}
+}
+
+// Peek applies the given consumer to each item in the stream
+func (ts TypedStream[T]) Peek(consumer func(item T), opts ...Option) TypedStream[T] {
+	return TypedStream[T]{s: ts.s.Peek(func(item any) {
+		consumer(cast[T](item))
+	}, opts...)}
+}
+
+// AnyMatch returns true if any item in the stream matches the given predicate, otherwise false.
+func (ts TypedStream[T]) AnyMatch(match func(item T) bool, opts ...Option) bool {
+	return ts.s.AnyMatch(func(item any) bool {
+		return match(cast[T](item))
+	}, opts...)
+}
+
+// AllMatch returns true if all items in the stream match the given predicate, otherwise false.
+func (ts TypedStream[T]) AllMatch(match func(item T) bool, opts ...Option) bool {
+	return ts.s.AllMatch(func(item any) bool {
+		return match(cast[T](item))
+	}, opts...)
+}
+
+// NoneMatch returns true if no item in the stream matches the given predicate, otherwise false.
+func (ts TypedStream[T]) NoneMatch(match func(item T) bool, opts ...Option) bool {
+	return ts.s.NoneMatch(func(item any) bool {
+		return match(cast[T](item))
+	}, opts...)
+}
+
+// FindFirst returns the first item in the stream.
+// If the stream is empty, the zero value is returned.
+func (ts TypedStream[T]) FindFirst(opts ...Option) (item T, found bool) {
+	iface, found := ts.s.FindFirst(opts...)
+	if !found {
+		return item, false
+	}
+	return cast[T](iface), true
+}
+
+// Count returns the number of items in the stream.
+func (ts TypedStream[T]) Count(opts ...Option) int64 {
+	return ts.s.Count(opts...)
+}
+
+// ForEach applies the given consumer to each item in the stream
+func (ts TypedStream[T]) ForEach(consumer func(item T), opts ...Option) {
+	ts.s.ForEach(func(item any) {
+		consumer(cast[T](item))
+	}, opts...)
+}
+
+// ForEachE is like ForEach, but the consumer can fail.
+// It returns the error of the stream, see Stream.Err.
+func (ts TypedStream[T]) ForEachE(consumer func(item T) error, opts ...Option) error {
+	return ts.s.ForEachE(func(item any) error {
+		return consumer(cast[T](item))
+	}, opts...)
+}
+
+// ToSlice returns the stream as a slice
+func (ts TypedStream[T]) ToSlice(opts ...Option) []T {
+	ifaces := ts.s.ToIfaceSlice(opts...)
+	items := make([]T, 0, len(ifaces))
+	for _, item := range ifaces {
+		items = append(items, cast[T](item))
+	}
+	return items
+}
+
+// ApplyOptions applies the given options to the stream
+func (ts TypedStream[T]) ApplyOptions(opts ...Option) TypedStream[T] {
+	ts.s.ApplyOptions(opts...)
+	return ts
+}
+
+// Close closes the stream
+func (ts TypedStream[T]) Close() {
+	ts.s.Close()
+}
+
+// Err returns the error that stopped the stream, see Stream.Err.
+func (ts TypedStream[T]) Err() error {
+	return ts.s.Err()
+}
+
+// Map applies the given mapper to each item in the stream
+func Map[T any, R any](ts TypedStream[T], mapper func(item T) R, opts ...Option) TypedStream[R] {
+	return TypedStream[R]{s: ts.s.Map(func(item any) any {
+		return mapper(cast[T](item))
+	}, opts...)}
+}
+
+// MapE is like Map, but the mapper can fail.
+// The failed item is handled by the error policy of the stream, see WithErrorPolicy.
+func MapE[T any, R any](ts TypedStream[T], mapper func(item T) (R, error), opts ...Option) TypedStream[R] {
+	return TypedStream[R]{s: ts.s.MapE(func(item any) (any, error) {
+		return mapper(cast[T](item))
+	}, opts...)}
+}
+
+// FlatMap applies the given mapper to each item in the stream, and flattens the returned streams
+func FlatMap[T any, R any](ts TypedStream[T], mapper func(item T) TypedStream[R], opts ...Option) TypedStream[R] {
+	return TypedStream[R]{s: ts.s.FlatMap(func(item any) Stream {
+		return mapper(cast[T](item)).s
+	}, opts...)}
+}
+
+// Reduce reduces the stream to a single value by the given accumulator function.
+func Reduce[T any, R any](ts TypedStream[T], identity R, accumulator func(identity R, item T) R, opts ...Option) R {
+	return cast[R](ts.s.Reduce(identity, func(identity any, item any) any {
+		return accumulator(cast[R](identity), cast[T](item))
+	}, opts...))
+}
+
+// Collect collects the stream by the given collector.
+//
+// If the collector is a collector.ParallelCollector, such as NewSumCollector,
+// each worker of a parallel stream accumulates into its own container, see Stream.CollectWithCombiner.
+//
+// Note: If the stream is parallel, other collectors must be routine-safe, such as NewSumCollectorInParallel.
+func Collect[T any, A any, R any](ts TypedStream[T], c collector.Collector[T, A, R]) R {
+	supplier, accumulator := c.Supplier(), c.Accumulator()
+	supply := func() any {
+		return supplier()
+	}
+	accumulate := func(container, item any) {
+		accumulator(container.(A), cast[T](item))
+	}
+	identity := func(container any) any {
+		return container
+	}
+
+	var container any
+	if pc, ok := c.(collector.ParallelCollector[T, A, R]); ok {
+		combiner := pc.Combiner()
+		container = ts.s.CollectWithCombiner(supply, accumulate, func(a, b any) any {
+			return combiner(a.(A), b.(A))
+		}, identity)
+	} else {
+		container = ts.s.Collect(supply, accumulate, identity)
+	}
+	return c.Finisher()(container.(A))
+}
+
+// Window groups the items into windows of the given size, see Stream.Window.
+func Window[T any](ts TypedStream[T], size int, opts ...Option) TypedStream[[]T] {
+	return typedWindows[T](ts.s.Window(size, opts...))
+}
+
+// SlidingWindow emits a window of the given size every step items, see Stream.SlidingWindow.
+func SlidingWindow[T any](ts TypedStream[T], size, step int, opts ...Option) TypedStream[[]T] {
+	return typedWindows[T](ts.s.SlidingWindow(size, step, opts...))
+}
+
+// TimeWindow emits a window when it reaches the given size or the given duration, see Stream.TimeWindow.
+func TimeWindow[T any](ts TypedStream[T], size int, duration time.Duration, opts ...Option) TypedStream[[]T] {
+	return typedWindows[T](ts.s.TimeWindow(size, duration, opts...))
+}
+
+// SessionWindow emits a window when no item arrives within the given gap, see Stream.SessionWindow.
+func SessionWindow[T any](ts TypedStream[T], gap time.Duration, opts ...Option) TypedStream[[]T] {
+	return typedWindows[T](ts.s.SessionWindow(gap, opts...))
+}
+
+// typedWindows converts the []any windows of the given stream to []T.
+// The windows are converted in a single goroutine, so that they are emitted in order even if the stream is parallel.
+func typedWindows[T any](s Stream) TypedStream[[]T] {
+	cs := s.(*concurrentStream)
+	return TypedStream[[]T]{s: cs.doStreamWithOptionSync(func(item any, out chan<- any) {
+		window := item.([]any)
+		typed := make([]T, 0, len(window))
+		for _, each := range window {
+			typed = append(typed, cast[T](each))
+		}
+		cs.send(out, typed)
+	}, false)}
+}
+
+// cast converts the item to T, a nil item is converted to the zero value of T,
+// which is a nil interface if T is an interface type.
+func cast[T any](item any) T {
+	if item == nil {
+		var zero T
+		return zero
+	}
+	return item.(T)
+}
@@ -0,0 +1,149 @@
+package stream
+
+import (
+	"errors"
+	"github.com/carter-ya/go-tools/stream/collector"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestTypedStream_Map(t *testing.T) {
+	actual := Map(Of([]int64{1, 2, 3, 4}), func(item int64) string {
+		return strconv.FormatInt(item*2, 10)
+	}).ToSlice()
+	require.Equal(t, []string{"2", "4", "6", "8"}, actual)
+
+	actual = Map(Of([]int64{1, 2, 3, 4}, WithParallelism(4)), func(item int64) string {
+		return strconv.FormatInt(item*2, 10)
+	}).ToSlice()
+	require.ElementsMatch(t, []string{"2", "4", "6", "8"}, actual)
+}
+
+func TestTypedStream_MapE(t *testing.T) {
+	s := MapE(Of([]string{"1", "2", "x"}), func(item string) (int, error) {
+		return strconv.Atoi(item)
+	})
+	require.Equal(t, []int{1, 2}, s.ToSlice())
+
+	var numErr *strconv.NumError
+	require.True(t, errors.As(s.Err(), &numErr))
+}
+
+func TestTypedStream_FilterAndSort(t *testing.T) {
+	actual := Of([]int{5, 3, 8, 1, 9, 2}).
+		Filter(func(item int) bool {
+			return item%2 == 1
+		}).
+		Sort(func(a, b int) bool {
+			return a < b
+		}).
+		ToSlice()
+	require.Equal(t, []int{1, 3, 5, 9}, actual)
+}
+
+func TestTypedStream_FindFirst(t *testing.T) {
+	item, found := Of([]int{}).FindFirst()
+	require.False(t, found)
+	require.Equal(t, 0, item)
+
+	item, found = Of([]int{3, 4}).FindFirst()
+	require.True(t, found)
+	require.Equal(t, 3, item)
+}
+
+func TestTypedStream_Reduce(t *testing.T) {
+	sum := Reduce(Of([]int{1, 2, 3, 4}), 0, func(identity int, item int) int {
+		return identity + item
+	})
+	require.Equal(t, 10, sum)
+}
+
+func TestTypedStream_Collect(t *testing.T) {
+	require.Equal(t, int64(10), Collect(Of([]int64{1, 2, 3, 4}), collector.NewSumCollector(collector.Identify[int64]())))
+	require.Equal(t, 2.5, Collect(Of([]int64{1, 2, 3, 4}), collector.NewAvgCollector(func(item int64) float64 {
+		return float64(item)
+	})))
+	require.Equal(t, map[bool][]int64{true: {1, 3}, false: {2, 4}}, Collect(Of([]int64{1, 2, 3, 4}),
+		collector.NewGroupByCollector(func(item int64) bool {
+			return item%2 == 1
+		}),
+	))
+	require.Equal(t, int64(10), Collect(Of([]int64{1, 2, 3, 4}, WithParallelism(4)),
+		collector.NewSumCollectorInParallel(collector.Identify[int64]())),
+	)
+}
+
+func TestTypedStream_CollectWithCombiner(t *testing.T) {
+	items := Range[int64](0, 10000).ToIfaceSlice()
+	numbers := make([]int64, 0, len(items))
+	for _, item := range items {
+		numbers = append(numbers, item.(int64))
+	}
+	numbersOf := func() TypedStream[int64] {
+		return Of(numbers, WithParallelism(4))
+	}
+
+	require.Equal(t, int64(49995000), Collect(numbersOf(), collector.NewSumCollector(collector.Identify[int64]())))
+	require.Equal(t, 10000, Collect(numbersOf(), collector.NewCountCollector[int64]()))
+	require.Equal(t, int64(4999), Collect(numbersOf(), collector.NewAvgCollector(collector.Identify[int64]())))
+	require.Equal(t, int64(0), Collect(numbersOf(), collector.NewMinCollector(collector.Identify[int64]())))
+	require.Equal(t, int64(9999), Collect(numbersOf(), collector.NewMaxCollector(collector.Identify[int64]())))
+	collected := Collect(numbersOf(), collector.NewToSliceCollector[int64](0))
+	sort.Slice(collected, func(i, j int) bool {
+		return collected[i] < collected[j]
+	})
+	require.Equal(t, numbers, collected)
+	require.Len(t, Collect(numbersOf(), collector.NewToMapCollector(0, collector.Identify[int64]())), 10000)
+
+	groups := Collect(numbersOf(), collector.NewGroupByCollector(func(item int64) int64 {
+		return item % 3
+	}))
+	require.Len(t, groups, 3)
+	require.Len(t, groups[0], 3334)
+	require.Len(t, groups[1], 3333)
+	require.Len(t, groups[2], 3333)
+}
+
+func TestTypedStream_Untyped(t *testing.T) {
+	ts := OfStream[int64](Range[int64](0, 4))
+	require.Equal(t, []int64{0, 1, 2, 3}, ts.ToSlice())
+
+	require.Equal(t, []any{1, 2}, Of([]int{1, 2}).Untyped().ToIfaceSlice())
+}
+
+func TestTypedStream_Window(t *testing.T) {
+	require.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, Window(Of([]int{1, 2, 3, 4, 5}), 2).ToSlice())
+	require.Equal(t, [][]int{{1, 2}, {2, 3}}, SlidingWindow(Of([]int{1, 2, 3}), 2, 1).ToSlice())
+
+	// the windows of a parallel stream are emitted in order
+	numbers := make([]int, 1000)
+	for i := range numbers {
+		numbers[i] = i
+	}
+	windows := Window(Of(numbers, WithParallelism(8)), 10).ToSlice()
+	require.Len(t, windows, 100)
+	for i, window := range windows {
+		require.Equal(t, numbers[i*10:i*10+10], window)
+	}
+}
+
+func TestTypedStream_NilInterfaces(t *testing.T) {
+	err := errors.New("failed")
+	errs := []error{nil, err, nil}
+	require.Equal(t, errs, Of(errs).ToSlice())
+	require.Equal(t, [][]error{{nil, err}, {nil}}, Window(Of(errs), 2).ToSlice())
+	require.Equal(t, []error{nil, nil}, Of(errs).Filter(func(item error) bool {
+		return item == nil
+	}).ToSlice())
+	require.Equal(t, []error{err, nil, err}, Map(Of(errs), func(item error) error {
+		if item == nil {
+			return err
+		}
+		return nil
+	}).ToSlice())
+	first, found := Of(errs).FindFirst()
+	require.True(t, found)
+	require.Nil(t, first)
+}